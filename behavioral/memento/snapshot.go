@@ -0,0 +1,368 @@
+package memento
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// TagName is the struct tag read by Snapshot, Restore and Diff.
+//
+//	Secret string `memento:"-"`       // never captured nor restored
+//	cache  []int  `memento:"include"` // unexported, but captured anyway
+const TagName = "memento"
+
+// ErrNotStruct is returned when the value given to Snapshot or Restore is not
+// a struct or a pointer to a struct.
+var ErrNotStruct = errors.New("memento: value must be a struct or a pointer to a struct")
+
+// ErrTypeMismatch is returned when a snapshot is restored into, or diffed
+// against, a value of a different type.
+var ErrTypeMismatch = errors.New("memento: snapshot type mismatch")
+
+// Memento is a deep copy of a struct taken by Snapshot. It is immutable from
+// the outside: the only way to get the state back is through Restore.
+type Memento struct {
+	value reflect.Value
+}
+
+// Type returns the type of the struct captured by the memento, or nil for the
+// zero Memento.
+func (m Memento) Type() reflect.Type {
+	if !m.value.IsValid() {
+		return nil
+	}
+	return m.value.Type()
+}
+
+// Snapshot deep-copies the struct pointed by (or passed as) v. Exported fields
+// are captured unless tagged `memento:"-"`, unexported fields only when tagged
+// `memento:"include"`. Maps, slices, arrays, pointers and interfaces are copied
+// recursively and shared or cyclic pointers keep their shape in the copy.
+//
+// The field rules only apply to struct types declared in the same package as
+// the captured struct, since those are the only ones the caller can tag.
+// Structs from other packages, such as time.Time, are copied as a whole value.
+func Snapshot(v any) (Memento, error) {
+	src := reflect.ValueOf(v)
+	if !src.IsValid() {
+		return Memento{}, ErrNotStruct
+	}
+	c := newCopier(src.Type())
+	if src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return Memento{}, ErrNotStruct
+		}
+		src = src.Elem()
+	}
+	if src.Kind() != reflect.Struct {
+		return Memento{}, ErrNotStruct
+	}
+	dst := reflect.New(src.Type())
+	if src.CanAddr() {
+		c.seen[visit{src.Addr().Pointer(), dst.Type()}] = dst
+	}
+	c.copy(dst.Elem(), addressable(src))
+	return Memento{value: dst.Elem()}, nil
+}
+
+// Restore copies the state held by m back into dst, which must be a non-nil
+// pointer to a struct of the same type the snapshot was taken from. Fields
+// excluded from the snapshot keep their current value in dst.
+func Restore(dst any, m Memento) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Pointer || d.IsNil() || d.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	if !m.value.IsValid() || d.Elem().Type() != m.value.Type() {
+		return fmt.Errorf("%w: cannot restore %v into %v", ErrTypeMismatch, m.Type(), d.Elem().Type())
+	}
+	c := newCopier(d.Type())
+	c.seen[visit{m.value.Addr().Pointer(), d.Type()}] = d
+	c.copy(d.Elem(), m.value)
+	return nil
+}
+
+// copier holds the already copied pointers and maps so that cycles and
+// shared references are reproduced instead of followed forever.
+type copier struct {
+	pkg  string
+	seen map[visit]reflect.Value
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func newCopier(root reflect.Type) *copier {
+	return &copier{pkg: rootPkg(root), seen: make(map[visit]reflect.Value)}
+}
+
+// copy deep-copies src into dst. dst must be settable.
+func (c *copier) copy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if foreign(src.Type(), c.pkg) {
+			dst.Set(src)
+			return
+		}
+		src = addressable(src)
+		t := src.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !captured(t.Field(i)) {
+				continue
+			}
+			c.copy(unlock(dst.Field(i)), unlock(src.Field(i)))
+		}
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		key := visit{src.Pointer(), src.Type()}
+		if p, ok := c.seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[key] = p
+		c.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		key := visit{src.Pointer(), src.Type()}
+		if m, ok := c.seen[key]; ok {
+			dst.Set(m)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.seen[key] = m
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			c.copy(k, iter.Key())
+			e := reflect.New(src.Type().Elem()).Elem()
+			c.copy(e, iter.Value())
+			m.SetMapIndex(k, e)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		c.copy(e, src.Elem())
+		dst.Set(e)
+	default:
+		// Scalars are copied by value; channels, funcs and unsafe pointers
+		// have no meaningful deep copy and are shared.
+		dst.Set(src)
+	}
+}
+
+// captured reports whether a struct field takes part in snapshots.
+func captured(f reflect.StructField) bool {
+	switch f.Tag.Get(TagName) {
+	case "-":
+		return false
+	case "include":
+		return true
+	}
+	return f.IsExported()
+}
+
+// rootPkg returns the package that declares the struct t, or points to.
+func rootPkg(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath()
+}
+
+// foreign reports whether the struct type t is declared outside pkg. Its
+// fields cannot carry memento tags, so it is handled as an opaque value.
+// Unnamed struct types are spelled out by the caller and are never foreign.
+func foreign(t reflect.Type, pkg string) bool {
+	return t.PkgPath() != "" && t.PkgPath() != pkg
+}
+
+// addressable returns v itself when it is addressable, or an addressable copy
+// otherwise, so that unexported fields can be reached through unlock.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// unlock gives read/write access to a field of an addressable struct even
+// when the field is unexported.
+func unlock(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// Change is a single field that differs between two snapshots. Path uses Go
+// selector syntax, for example "Address.City", "Tags[2]" or `Meta["k"]`. Old
+// or New is nil when the element does not exist on that side.
+type Change struct {
+	Path string
+	Old  any
+	New  any
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff lists every captured field that differs between two snapshots of the
+// same type, in a deterministic order. It is meant for audit logs.
+func Diff(before, after Memento) ([]Change, error) {
+	if !before.value.IsValid() || !after.value.IsValid() || before.value.Type() != after.value.Type() {
+		return nil, fmt.Errorf("%w: cannot diff %v against %v", ErrTypeMismatch, before.Type(), after.Type())
+	}
+	d := &differ{pkg: rootPkg(before.value.Type()), seen: make(map[[2]visit]bool)}
+	pt := reflect.PointerTo(before.value.Type())
+	d.seen[[2]visit{{before.value.Addr().Pointer(), pt}, {after.value.Addr().Pointer(), pt}}] = true
+	d.diff("", before.value, after.value)
+	return d.changes, nil
+}
+
+type differ struct {
+	pkg     string
+	changes []Change
+	seen    map[[2]visit]bool
+}
+
+func (d *differ) add(path string, a, b reflect.Value) {
+	d.changes = append(d.changes, Change{Path: strings.TrimPrefix(path, "."), Old: iface(a), New: iface(b)})
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Struct:
+		a, b = addressable(a), addressable(b)
+		if foreign(a.Type(), d.pkg) {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				d.add(path, a, b)
+			}
+			return
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !captured(t.Field(i)) {
+				continue
+			}
+			d.diff(path+"."+t.Field(i).Name, unlock(a.Field(i)), unlock(b.Field(i)))
+		}
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, a, b)
+			}
+			return
+		}
+		key := [2]visit{{a.Pointer(), a.Type()}, {b.Pointer(), b.Type()}}
+		if d.seen[key] {
+			return
+		}
+		d.seen[key] = true
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			if !a.IsNil() || !b.IsNil() {
+				d.add(path, a, b)
+			}
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			d.add(path, a, b)
+			return
+		}
+		n := max(a.Len(), b.Len())
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.add(p, reflect.Value{}, b.Index(i))
+			case i >= b.Len():
+				d.add(p, a.Index(i), reflect.Value{})
+			default:
+				d.diff(p, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			d.add(path, a, b)
+			return
+		}
+		keys := a.MapKeys()
+		for _, k := range b.MapKeys() {
+			if !a.MapIndex(k).IsValid() {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(iface(keys[i])) < fmt.Sprint(iface(keys[j]))
+		})
+		for _, k := range keys {
+			p := fmt.Sprintf("%s[%#v]", path, iface(k))
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			if !av.IsValid() || !bv.IsValid() {
+				d.add(p, av, bv)
+				continue
+			}
+			d.diff(p, av, bv)
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.add(path, a, b)
+		}
+	default:
+		if !a.Equal(b) {
+			d.add(path, a, b)
+		}
+	}
+}
+
+// iface returns the value held by v, or nil for an invalid or nil value.
+func iface(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
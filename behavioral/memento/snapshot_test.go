@@ -0,0 +1,184 @@
+package memento
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+type account struct {
+	Owner    string
+	Balance  int
+	Password string `memento:"-"`
+	Tags     []string
+	Limits   map[string]int
+	Home     *address
+	Extra    any
+	Head     *node
+	visits   int `memento:"include"`
+	cache    []int
+}
+
+func newAccount() *account {
+	n1 := &node{Name: "first"}
+	n2 := &node{Name: "second", Next: n1}
+	n1.Next = n2
+	return &account{
+		Owner:    "alice",
+		Balance:  100,
+		Password: "secret",
+		Tags:     []string{"gold"},
+		Limits:   map[string]int{"daily": 10},
+		Home:     &address{City: "Lisbon"},
+		Extra:    address{City: "Porto"},
+		Head:     n1,
+		visits:   3,
+		cache:    []int{1, 2},
+	}
+}
+
+func TestSnapshot_DeepCopy(t *testing.T) {
+	a := newAccount()
+	m, err := Snapshot(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Owner = "bob"
+	a.Tags[0] = "silver"
+	a.Limits["daily"] = 99
+	a.Home.City = "Madrid"
+	a.Head.Name = "changed"
+	a.visits = 7
+	a.Password = "new secret"
+	a.cache = nil
+
+	if err := Restore(a, m); err != nil {
+		t.Fatal(err)
+	}
+	if a.Owner != "alice" || a.Tags[0] != "gold" || a.Limits["daily"] != 10 || a.Home.City != "Lisbon" {
+		t.Errorf("Exported fields were not restored: %+v", a)
+	}
+	if a.Head.Name != "first" || a.Head.Next.Next != a.Head {
+		t.Error("The cyclic list was not restored with its shape")
+	}
+	if a.visits != 3 {
+		t.Errorf("Tagged unexported field must be restored, got %d", a.visits)
+	}
+	if a.Password != "new secret" {
+		t.Error("Excluded field must keep its current value")
+	}
+	if a.cache != nil {
+		t.Error("Untagged unexported field must not be restored")
+	}
+}
+
+func TestSnapshot_Errors(t *testing.T) {
+	if _, err := Snapshot(nil); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Expected ErrNotStruct for nil, got %v", err)
+	}
+	if _, err := Snapshot(3); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Expected ErrNotStruct, got %v", err)
+	}
+	if _, err := Snapshot((*account)(nil)); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Expected ErrNotStruct for a nil pointer, got %v", err)
+	}
+	m, _ := Snapshot(address{City: "Lisbon"})
+	if err := Restore(address{}, m); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Expected ErrNotStruct when restoring into a value, got %v", err)
+	}
+	if err := Restore(&account{}, m); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := newAccount()
+	before, _ := Snapshot(a)
+	a.Balance = 50
+	a.Password = "ignored"
+	a.Tags = append(a.Tags, "vip")
+	a.Limits["monthly"] = 300
+	a.Home = nil
+	a.visits = 4
+	after, _ := Snapshot(a)
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Balance: 100 -> 50",
+		"Tags[1]: <nil> -> vip",
+		`Limits["monthly"]: <nil> -> 300`,
+		"Home: &{Lisbon} -> <nil>",
+		"visits: 3 -> 4",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, c := range changes {
+		if c.String() != expected[i] {
+			t.Errorf("Change %d: expected %q, got %q", i, expected[i], c.String())
+		}
+	}
+
+	if _, err := Diff(before, Memento{}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+type event struct {
+	Name string
+	At   time.Time
+	Self *event
+}
+
+func TestSnapshot_ForeignStruct(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := &event{Name: "launch", At: at}
+	before, err := Snapshot(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.At = e.At.Add(time.Hour)
+	after, _ := Snapshot(e)
+
+	changes, _ := Diff(before, after)
+	if len(changes) != 1 || changes[0].Path != "At" {
+		t.Fatalf("Expected a single change on At, got %v", changes)
+	}
+	if err := Restore(e, before); err != nil {
+		t.Fatal(err)
+	}
+	if !e.At.Equal(at) {
+		t.Errorf("Expected At to be restored to %v, got %v", at, e.At)
+	}
+}
+
+func TestSnapshot_SelfReference(t *testing.T) {
+	e := &event{Name: "loop"}
+	e.Self = e
+	m, err := Snapshot(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out event
+	if err := Restore(&out, m); err != nil {
+		t.Fatal(err)
+	}
+	if out.Self != &out {
+		t.Error("A self-referencing struct must be restored as a self-cycle")
+	}
+	if changes, _ := Diff(m, m); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+}
@@ -0,0 +1,205 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Text renders the report as plain text, with tables aligned in columns.
+type Text struct{}
+
+func (Text) Header(w io.Writer, title, subtitle string) error {
+	_, err := fmt.Fprintf(w, "%s\n%s\n", title, strings.Repeat("=", len(title)))
+	if err == nil && subtitle != "" {
+		_, err = fmt.Fprintf(w, "%s\n", subtitle)
+	}
+	return err
+}
+
+func (t Text) Section(w io.Writer, c Content) error {
+	if _, err := fmt.Fprintf(w, "\n%s\n%s\n", c.Title, strings.Repeat("-", len(c.Title))); err != nil {
+		return err
+	}
+	return t.body(w, c)
+}
+
+func (t Text) Summary(w io.Writer, c Content) error {
+	return t.Section(w, c)
+}
+
+func (Text) Footer(w io.Writer, text string) error {
+	if text == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", text)
+	return err
+}
+
+func (Text) body(w io.Writer, c Content) error {
+	for _, p := range c.Paragraphs {
+		if _, err := fmt.Fprintf(w, "%s\n", p); err != nil {
+			return err
+		}
+	}
+	if c.Table == nil {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(c.Table.Columns, "\t"))
+	for _, row := range c.Table.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// Markdown renders the report as Markdown, with tables as pipe tables.
+type Markdown struct{}
+
+func (Markdown) Header(w io.Writer, title, subtitle string) error {
+	_, err := fmt.Fprintf(w, "# %s\n", title)
+	if err == nil && subtitle != "" {
+		_, err = fmt.Fprintf(w, "\n_%s_\n", subtitle)
+	}
+	return err
+}
+
+func (m Markdown) Section(w io.Writer, c Content) error {
+	if _, err := fmt.Fprintf(w, "\n## %s\n", c.Title); err != nil {
+		return err
+	}
+	return m.body(w, c)
+}
+
+func (m Markdown) Summary(w io.Writer, c Content) error {
+	return m.Section(w, c)
+}
+
+func (Markdown) Footer(w io.Writer, text string) error {
+	if text == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n---\n\n%s\n", text)
+	return err
+}
+
+func (Markdown) body(w io.Writer, c Content) error {
+	for _, p := range c.Paragraphs {
+		if _, err := fmt.Fprintf(w, "\n%s\n", p); err != nil {
+			return err
+		}
+	}
+	if c.Table == nil {
+		return nil
+	}
+	var b strings.Builder
+	row := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(escaped, " | "))
+	}
+	b.WriteString("\n")
+	row(c.Table.Columns)
+	b.WriteString("|" + strings.Repeat(" --- |", len(c.Table.Columns)) + "\n")
+	for _, r := range c.Table.Rows {
+		row(r)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlTemplates = template.Must(template.New("report").Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+{{- with .Subtitle}}
+<p class="subtitle">{{.}}</p>
+{{- end}}
+{{end -}}
+
+{{- define "body" -}}
+{{- range .Paragraphs}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Table}}
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+{{end -}}
+
+{{- define "section" -}}
+<section>
+<h2>{{.Title}}</h2>
+{{- template "body" .}}</section>
+{{end -}}
+
+{{- define "summary" -}}
+<section class="summary">
+<h2>{{.Title}}</h2>
+{{- template "body" .}}</section>
+{{end -}}
+
+{{- define "footer" -}}
+{{- with .}}<footer>{{.}}</footer>
+{{end -}}
+</body>
+</html>
+{{end -}}
+`))
+
+// HTML renders the report as an HTML page with html/template, so the content
+// of the providers is escaped.
+type HTML struct{}
+
+func (HTML) Header(w io.Writer, title, subtitle string) error {
+	return htmlTemplates.ExecuteTemplate(w, "header", struct{ Title, Subtitle string }{title, subtitle})
+}
+
+func (HTML) Section(w io.Writer, c Content) error {
+	return htmlTemplates.ExecuteTemplate(w, "section", c)
+}
+
+func (HTML) Summary(w io.Writer, c Content) error {
+	return htmlTemplates.ExecuteTemplate(w, "summary", c)
+}
+
+func (HTML) Footer(w io.Writer, text string) error {
+	return htmlTemplates.ExecuteTemplate(w, "footer", text)
+}
+
+// CSV renders only the tables of the report: every table starts with a
+// record holding the section title, then its columns and rows, and tables
+// are separated by an empty record. Header and footer are left out.
+type CSV struct{}
+
+func (CSV) Header(io.Writer, string, string) error { return nil }
+func (CSV) Footer(io.Writer, string) error         { return nil }
+
+func (CSV) Section(w io.Writer, c Content) error {
+	if c.Table == nil {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{c.Title})
+	cw.Write(c.Table.Columns)
+	cw.WriteAll(c.Table.Rows)
+	cw.Write(nil)
+	cw.Flush()
+	return cw.Error()
+}
+
+func (c CSV) Summary(w io.Writer, content Content) error {
+	return c.Section(w, content)
+}
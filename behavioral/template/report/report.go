@@ -0,0 +1,100 @@
+// Package report applies the template method to recurring reports: the
+// skeleton (header, body sections, summary, footer) is fixed by Report, the
+// content comes from pluggable section providers and the output format from
+// interchangeable renderers.
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// Table is tabular data inside a section.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Content is what a section shows: some paragraphs followed by an optional
+// table.
+type Content struct {
+	Title      string
+	Paragraphs []string
+	Table      *Table
+}
+
+// SectionProvider provides the content of a section, the same way a
+// template.MessageRetriever provides the message of template.Template.
+type SectionProvider interface {
+	Content() (Content, error)
+}
+
+// SectionFunc adapts a function to a SectionProvider.
+type SectionFunc func() (Content, error)
+
+func (f SectionFunc) Content() (Content, error) {
+	return f()
+}
+
+// Static is a SectionProvider that always returns the same content.
+type Static Content
+
+func (s Static) Content() (Content, error) {
+	return Content(s), nil
+}
+
+// Renderer writes every part of the skeleton in a given format. Report calls
+// its methods in order: Header once, Section for every body section, Summary
+// when there is one, and Footer once.
+type Renderer interface {
+	Header(w io.Writer, title, subtitle string) error
+	Section(w io.Writer, c Content) error
+	Summary(w io.Writer, c Content) error
+	Footer(w io.Writer, text string) error
+}
+
+// Report is the skeleton of a report.
+type Report struct {
+	Title    string
+	Subtitle string
+	Sections []SectionProvider
+	// Summary is optional.
+	Summary SectionProvider
+	Footer  string
+}
+
+// Render writes the report with r. Providers are asked for their content
+// before anything is written, so a failing provider leaves w untouched.
+func (rp *Report) Render(w io.Writer, r Renderer) error {
+	sections := make([]Content, len(rp.Sections))
+	for i, p := range rp.Sections {
+		c, err := p.Content()
+		if err != nil {
+			return fmt.Errorf("section %d: %w", i, err)
+		}
+		sections[i] = c
+	}
+	var summary *Content
+	if rp.Summary != nil {
+		c, err := rp.Summary.Content()
+		if err != nil {
+			return fmt.Errorf("summary: %w", err)
+		}
+		summary = &c
+	}
+
+	if err := r.Header(w, rp.Title, rp.Subtitle); err != nil {
+		return err
+	}
+	for _, c := range sections {
+		if err := r.Section(w, c); err != nil {
+			return err
+		}
+	}
+	if summary != nil {
+		if err := r.Summary(w, *summary); err != nil {
+			return err
+		}
+	}
+	return r.Footer(w, rp.Footer)
+}
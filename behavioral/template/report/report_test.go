@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func sampleReport() *Report {
+	return &Report{
+		Title:    "Monthly sales",
+		Subtitle: "March 2026",
+		Sections: []SectionProvider{
+			Static{
+				Title:      "Overview",
+				Paragraphs: []string{"Sales grew 4% over February.", "Returns <5% & falling."},
+			},
+			SectionFunc(func() (Content, error) {
+				return Content{
+					Title: "By region",
+					Table: &Table{
+						Columns: []string{"Region", "Orders", "Revenue"},
+						Rows: [][]string{
+							{"North", "120", "15,300.00"},
+							{"South", "98", "11,020.50"},
+							{"Islands | overseas", "7", "845.10"},
+						},
+					},
+				}, nil
+			}),
+		},
+		Summary: Static{
+			Title: "Summary",
+			Table: &Table{
+				Columns: []string{"Orders", "Revenue"},
+				Rows:    [][]string{{"225", "27,165.60"}},
+			},
+		},
+		Footer: "Generated by the report template.",
+	}
+}
+
+func TestReport_Render(t *testing.T) {
+	renderers := map[string]Renderer{
+		"report.txt":  Text{},
+		"report.md":   Markdown{},
+		"report.html": HTML{},
+		"report.csv":  CSV{},
+	}
+	for name, r := range renderers {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := sampleReport().Render(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Errorf("Output doesn't match %s:\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestReport_ProviderError(t *testing.T) {
+	errDown := errors.New("database down")
+	rp := sampleReport()
+	rp.Sections = append(rp.Sections, SectionFunc(func() (Content, error) {
+		return Content{}, errDown
+	}))
+	var buf bytes.Buffer
+	err := rp.Render(&buf, Text{})
+	if !errors.Is(err, errDown) {
+		t.Fatalf("Expected the provider error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Error("Nothing must be written when a provider fails")
+	}
+}
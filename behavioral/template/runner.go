@@ -0,0 +1,181 @@
+package template
+
+import "fmt"
+
+// Step is a named step of an algorithm. Do works on the state shared by every
+// step of the run.
+type Step[T any] struct {
+	Name string
+	Do   func(state T) error
+}
+
+// Hooks are called by Run around every step. Before can veto a step by
+// returning an error, which is then handled like an error of the step itself.
+type Hooks[T any] interface {
+	Before(step string, state T) error
+	After(step string, state T)
+	OnError(step string, state T, err error)
+}
+
+// Algorithm is the skeleton run by Run: an ordered list of steps and the
+// hooks to call around them.
+type Algorithm[T any] interface {
+	Hooks[T]
+	Steps() []Step[T]
+}
+
+// NoHooks is the default implementation of Hooks, which does nothing. Embed it
+// in an algorithm and override only the hooks needed.
+type NoHooks[T any] struct{}
+
+func (NoHooks[T]) Before(string, T) error   { return nil }
+func (NoHooks[T]) After(string, T)          {}
+func (NoHooks[T]) OnError(string, T, error) {}
+
+// StepError is returned by Run when a step fails. It tells which step
+// stopped the run.
+type StepError struct {
+	Step  string
+	Index int
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d %q failed: %v", e.Index, e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Run executes the steps of a in order. The first error, either from a step
+// or from its Before hook, calls OnError, stops the run and is returned as a
+// *StepError. After is only called for the steps that succeeded.
+func Run[T any](a Algorithm[T], state T) error {
+	for i, step := range a.Steps() {
+		err := a.Before(step.Name, state)
+		if err == nil && step.Do != nil {
+			err = step.Do(state)
+		}
+		if err != nil {
+			a.OnError(step.Name, state, err)
+			return &StepError{Step: step.Name, Index: i, Err: err}
+		}
+		a.After(step.Name, state)
+	}
+	return nil
+}
+
+// Runner is an Algorithm assembled at runtime from functions. The zero value
+// is an empty algorithm without hooks.
+type Runner[T any] struct {
+	steps   []Step[T]
+	before  func(string, T) error
+	after   func(string, T)
+	onError func(string, T, error)
+}
+
+// NewRunner returns a Runner with the given steps.
+func NewRunner[T any](steps ...Step[T]) *Runner[T] {
+	return &Runner[T]{steps: steps}
+}
+
+// Step appends a step and returns the runner to allow chaining.
+func (r *Runner[T]) Step(name string, do func(T) error) *Runner[T] {
+	r.steps = append(r.steps, Step[T]{Name: name, Do: do})
+	return r
+}
+
+// Replace swaps the implementation of the named step, keeping its position.
+// It returns false when there is no such step.
+func (r *Runner[T]) Replace(name string, do func(T) error) bool {
+	for i := range r.steps {
+		if r.steps[i].Name == name {
+			r.steps[i].Do = do
+			return true
+		}
+	}
+	return false
+}
+
+// BeforeEach sets the Before hook.
+func (r *Runner[T]) BeforeEach(f func(step string, state T) error) *Runner[T] {
+	r.before = f
+	return r
+}
+
+// AfterEach sets the After hook.
+func (r *Runner[T]) AfterEach(f func(step string, state T)) *Runner[T] {
+	r.after = f
+	return r
+}
+
+// OnErrorDo sets the OnError hook.
+func (r *Runner[T]) OnErrorDo(f func(step string, state T, err error)) *Runner[T] {
+	r.onError = f
+	return r
+}
+
+// Steps returns a copy of the steps, so callers can't reorder the runner.
+func (r *Runner[T]) Steps() []Step[T] {
+	return append([]Step[T](nil), r.steps...)
+}
+
+func (r *Runner[T]) Before(step string, state T) error {
+	if r.before == nil {
+		return nil
+	}
+	return r.before(step, state)
+}
+
+func (r *Runner[T]) After(step string, state T) {
+	if r.after != nil {
+		r.after(step, state)
+	}
+}
+
+func (r *Runner[T]) OnError(step string, state T, err error) {
+	if r.onError != nil {
+		r.onError(step, state, err)
+	}
+}
+
+// Run executes the runner steps. See the Run function.
+func (r *Runner[T]) Run(state T) error {
+	return Run[T](r, state)
+}
+
+// Greeting is the algorithm of Template rewritten as an Algorithm: the
+// first and third words have defaults that can be overridden through the
+// First and Third fields, while the second one comes from a MessageRetriever.
+type Greeting struct {
+	NoHooks[*[]string]
+	Retriever MessageRetriever
+	First     func() string
+	Third     func() string
+}
+
+// Steps returns the three steps of the greeting, using "hello" and
+// "template" when First or Third are not set.
+func (g *Greeting) Steps() []Step[*[]string] {
+	word := func(f func() string, def string) func(*[]string) error {
+		return func(words *[]string) error {
+			if f != nil {
+				def = f()
+			}
+			*words = append(*words, def)
+			return nil
+		}
+	}
+	return []Step[*[]string]{
+		{Name: "first", Do: word(g.First, "hello")},
+		{Name: "message", Do: func(words *[]string) error {
+			if g.Retriever == nil {
+				return fmt.Errorf("no message retriever")
+			}
+			*words = append(*words, g.Retriever.Message())
+			return nil
+		}},
+		{Name: "third", Do: word(g.Third, "template")},
+	}
+}
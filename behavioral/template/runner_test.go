@@ -0,0 +1,114 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// auditedGreeting embeds Greeting and overrides two of its default hooks.
+type auditedGreeting struct {
+	Greeting
+	log []string
+}
+
+func (a *auditedGreeting) After(step string, words *[]string) {
+	a.log = append(a.log, "after "+step)
+}
+
+func (a *auditedGreeting) OnError(step string, words *[]string, err error) {
+	a.log = append(a.log, "error "+step)
+}
+
+func TestRun_Greeting(t *testing.T) {
+	var words []string
+	g := &Greeting{Retriever: MessageRetrieverAdapter(func() string { return "world" })}
+	if err := Run[*[]string](g, &words); err != nil {
+		t.Fatal(err)
+	}
+	if res := strings.Join(words, " "); res != "hello world template" {
+		t.Errorf("Unexpected greeting '%s'", res)
+	}
+
+	words = nil
+	g.Third = func() string { return "runner" }
+	if err := Run[*[]string](g, &words); err != nil {
+		t.Fatal(err)
+	}
+	if res := strings.Join(words, " "); res != "hello world runner" {
+		t.Errorf("The third step was not overridden: '%s'", res)
+	}
+}
+
+func TestRun_OverriddenHooks(t *testing.T) {
+	var words []string
+	a := &auditedGreeting{}
+	err := Run[*[]string](a, &words)
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("Expected a *StepError, got %v", err)
+	}
+	if stepErr.Step != "message" || stepErr.Index != 1 {
+		t.Errorf("Expected the message step to fail, got %v", stepErr)
+	}
+	if strings.Join(a.log, ",") != "after first,error message" {
+		t.Errorf("Unexpected hook calls %v", a.log)
+	}
+	if len(words) != 1 {
+		t.Errorf("The run must stop at the failing step, got %v", words)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	errBoom := errors.New("boom")
+	var calls []string
+	r := NewRunner[*int]().
+		Step("one", func(n *int) error { *n++; return nil }).
+		Step("two", func(n *int) error { *n *= 10; return nil }).
+		Step("three", func(n *int) error { return errBoom }).
+		Step("four", func(n *int) error { *n = -1; return nil }).
+		BeforeEach(func(step string, n *int) error {
+			calls = append(calls, "before "+step)
+			return nil
+		}).
+		OnErrorDo(func(step string, n *int, err error) {
+			calls = append(calls, "error "+step)
+		})
+
+	n := 0
+	err := r.Run(&n)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Expected the step error to wrap errBoom, got %v", err)
+	}
+	if err.Error() != `step 2 "three" failed: boom` {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+	if n != 10 {
+		t.Errorf("Expected 10, got %d", n)
+	}
+	if strings.Join(calls, ",") != "before one,before two,before three,error three" {
+		t.Errorf("Unexpected hook calls %v", calls)
+	}
+
+	if !r.Replace("three", func(n *int) error { return nil }) {
+		t.Fatal("The three step should have been replaced")
+	}
+	if r.Replace("five", nil) {
+		t.Error("Replacing an unknown step must fail")
+	}
+	n = 0
+	if err := r.Run(&n); err != nil || n != -1 {
+		t.Errorf("Expected a complete run, got %d and %v", n, err)
+	}
+}
+
+func TestRunner_BeforeVeto(t *testing.T) {
+	ran := false
+	r := NewRunner(Step[*int]{Name: "guarded", Do: func(*int) error { ran = true; return nil }}).
+		BeforeEach(func(string, *int) error { return errors.New("not allowed") })
+	n := 0
+	if err := r.Run(&n); err == nil || ran {
+		t.Error("A failing Before hook must stop the step")
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"sort"
+
+	"github.com/antoniofmoliveira/patterns/behavioral/template/sorting"
 )
 
 type MyList []int
@@ -24,4 +27,8 @@ func main() {
 	fmt.Println(myList)
 	sort.Sort(myList)
 	fmt.Println(myList)
+
+	// The sorting package keeps the same idea with generic comparators
+	var other MyList = []int{6, 4, 2, 8, 1}
+	fmt.Println(sorting.TopK(other, 3, sorting.Compare[int](cmp.Compare[int]).Reverse()))
 }
@@ -0,0 +1,56 @@
+// Package sorting builds on the template method behind sort.Sort: the sorting
+// algorithms are fixed and callers only provide the comparison step. It adds
+// comparator chains, stable multi-key sorting, top-K selection, a parallel
+// sort and an external merge sort for data sets that don't fit in memory.
+package sorting
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Compare returns a negative number when a sorts before b, a positive number
+// when it sorts after and zero when they are equivalent, like cmp.Compare.
+type Compare[T any] func(a, b T) int
+
+// By compares values by the key extracted from them.
+func By[T any, K cmp.Ordered](key func(T) K) Compare[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// Then returns a comparator that breaks the ties of c with next.
+func (c Compare[T]) Then(next Compare[T]) Compare[T] {
+	return func(a, b T) int {
+		if r := c(a, b); r != 0 {
+			return r
+		}
+		return next(a, b)
+	}
+}
+
+// Reverse returns a comparator with the opposite order of c.
+func (c Compare[T]) Reverse() Compare[T] {
+	return func(a, b T) int {
+		return c(b, a)
+	}
+}
+
+// Chain combines comparators, the first one being the primary key.
+func Chain[T any](cmps ...Compare[T]) Compare[T] {
+	return func(a, b T) int {
+		for _, c := range cmps {
+			if r := c(a, b); r != 0 {
+				return r
+			}
+		}
+		return 0
+	}
+}
+
+// Stable sorts s by the given keys, in priority order, keeping the original
+// order of equivalent elements.
+func Stable[T any](s []T, keys ...Compare[T]) {
+	slices.SortStableFunc(s, Chain(keys...))
+}
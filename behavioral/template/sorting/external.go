@@ -0,0 +1,214 @@
+package sorting
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"slices"
+)
+
+// DefaultRunSize is the number of elements External keeps in memory when
+// RunSize is not set.
+const DefaultRunSize = 1 << 16
+
+// DefaultFanIn is the number of runs External merges at once when FanIn is
+// not set.
+const DefaultFanIn = 64
+
+// External is a stable external merge sort: the input is read in runs of at
+// most RunSize elements, every run is sorted in memory and spilled to a temp
+// file with encoding/gob, and the runs are finally merged with a heap. When
+// there are more than FanIn runs they are merged in passes of FanIn
+// consecutive runs into longer ones, so that the number of open files stays
+// bounded. T must be encodable by gob.
+type External[T any] struct {
+	Compare Compare[T]
+	// RunSize is the maximum number of elements held in memory at once.
+	RunSize int
+	// FanIn is the maximum number of runs merged, and files open, at once.
+	FanIn int
+	// TempDir is where the runs are spilled, os.TempDir() when empty.
+	TempDir string
+}
+
+// Sort reads every element of in and calls emit with them in sorted order.
+// It stops at the first error returned by emit. Temp files are removed before
+// returning.
+func (e External[T]) Sort(in iter.Seq[T], emit func(T) error) error {
+	size := e.RunSize
+	if size <= 0 {
+		size = DefaultRunSize
+	}
+	fanIn := e.FanIn
+	if fanIn < 2 {
+		fanIn = DefaultFanIn
+	}
+	var runs, files []string
+	defer func() {
+		for _, name := range files {
+			os.Remove(name)
+		}
+	}()
+
+	buf := make([]T, 0, size)
+	for v := range in {
+		buf = append(buf, v)
+		if len(buf) < size {
+			continue
+		}
+		name, err := e.spill(buf)
+		if err != nil {
+			return err
+		}
+		runs, files = append(runs, name), append(files, name)
+		buf = buf[:0]
+	}
+	if len(runs) == 0 {
+		slices.SortStableFunc(buf, e.Compare)
+		for _, v := range buf {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(buf) > 0 {
+		name, err := e.spill(buf)
+		if err != nil {
+			return err
+		}
+		runs, files = append(runs, name), append(files, name)
+	}
+	for len(runs) > fanIn {
+		var next []string
+		for chunk := range slices.Chunk(runs, fanIn) {
+			name, err := e.write(func(enc *gob.Encoder) error {
+				return e.merge(chunk, func(v T) error { return enc.Encode(&v) })
+			})
+			if err != nil {
+				return err
+			}
+			for _, done := range chunk {
+				os.Remove(done)
+			}
+			next, files = append(next, name), append(files, name)
+		}
+		runs = next
+	}
+	return e.merge(runs, emit)
+}
+
+// spill sorts a run and writes it to a new temp file.
+func (e External[T]) spill(run []T) (string, error) {
+	slices.SortStableFunc(run, e.Compare)
+	return e.write(func(enc *gob.Encoder) error {
+		for i := range run {
+			if err := enc.Encode(&run[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// write creates a new temp file and fills it with a run encoded by fill.
+func (e External[T]) write(fill func(*gob.Encoder) error) (name string, err error) {
+	f, err := os.CreateTemp(e.TempDir, "sorting-run-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	w := bufio.NewWriter(f)
+	if err := fill(gob.NewEncoder(w)); err != nil {
+		return "", err
+	}
+	return f.Name(), w.Flush()
+}
+
+// runReader is the head of a spilled run during the merge.
+type runReader[T any] struct {
+	dec   *gob.Decoder
+	head  T
+	index int
+}
+
+func (r *runReader[T]) next() (bool, error) {
+	var v T
+	if err := r.dec.Decode(&v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	r.head = v
+	return true, nil
+}
+
+// runHeap orders the runs by their head, and by run index on ties so that
+// the merge is stable.
+type runHeap[T any] struct {
+	runs    []*runReader[T]
+	compare Compare[T]
+}
+
+func (h *runHeap[T]) Len() int { return len(h.runs) }
+func (h *runHeap[T]) Less(i, j int) bool {
+	if c := h.compare(h.runs[i].head, h.runs[j].head); c != 0 {
+		return c < 0
+	}
+	return h.runs[i].index < h.runs[j].index
+}
+func (h *runHeap[T]) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap[T]) Push(x any)    { h.runs = append(h.runs, x.(*runReader[T])) }
+func (h *runHeap[T]) Pop() any {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
+
+func (e External[T]) merge(names []string, emit func(T) error) error {
+	h := &runHeap[T]{compare: e.Compare}
+	for i, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r := &runReader[T]{dec: gob.NewDecoder(bufio.NewReader(f)), index: i}
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.runs = append(h.runs, r)
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		r := h.runs[0]
+		if err := emit(r.head); err != nil {
+			return err
+		}
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package sorting
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// parallelThreshold is the size below which sorting in goroutines costs more
+// than it saves.
+const parallelThreshold = 1 << 13
+
+// Parallel sorts s with up to workers goroutines (GOMAXPROCS when workers <=
+// 0): the slice is cut in runs sorted concurrently, which are then merged in
+// pairs, also concurrently. It is not stable.
+func Parallel[T any](s []T, compare Compare[T], workers int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 || len(s) < parallelThreshold {
+		slices.SortFunc(s, compare)
+		return
+	}
+	runs := make([]int, 0, workers+1)
+	size := (len(s) + workers - 1) / workers
+	for i := 0; i < len(s); i += size {
+		runs = append(runs, i)
+	}
+	runs = append(runs, len(s))
+
+	var wg sync.WaitGroup
+	for i := 0; i < len(runs)-1; i++ {
+		wg.Add(1)
+		go func(run []T) {
+			defer wg.Done()
+			slices.SortFunc(run, compare)
+		}(s[runs[i]:runs[i+1]])
+	}
+	wg.Wait()
+
+	buf := make([]T, len(s))
+	src, dst := s, buf
+	for len(runs) > 2 {
+		merged := make([]int, 0, len(runs)/2+2)
+		for i := 0; i < len(runs)-1; i += 2 {
+			lo := runs[i]
+			merged = append(merged, lo)
+			if i+2 >= len(runs) {
+				copy(dst[lo:], src[lo:runs[i+1]])
+				continue
+			}
+			mid, hi := runs[i+1], runs[i+2]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				merge(dst[lo:hi], src[lo:mid], src[mid:hi], compare)
+			}()
+		}
+		merged = append(merged, len(s))
+		wg.Wait()
+		runs = merged
+		src, dst = dst, src
+	}
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// merge writes the sorted union of a and b into dst, taking from a first on
+// ties.
+func merge[T any](dst, a, b []T, compare Compare[T]) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if compare(b[j], a[i]) < 0 {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
@@ -0,0 +1,222 @@
+package sorting
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+)
+
+type employee struct {
+	Name   string
+	Dept   string
+	Salary int
+}
+
+func employees() []employee {
+	return []employee{
+		{"Ana", "sales", 3000},
+		{"Bruno", "it", 4000},
+		{"Carla", "sales", 3500},
+		{"Duarte", "it", 4000},
+		{"Eva", "hr", 2800},
+		{"Filipe", "sales", 3000},
+	}
+}
+
+func names(es []employee) []string {
+	out := make([]string, len(es))
+	for i, e := range es {
+		out[i] = e.Name
+	}
+	return out
+}
+
+func TestStable(t *testing.T) {
+	es := employees()
+	Stable(es,
+		By(func(e employee) string { return e.Dept }),
+		By(func(e employee) int { return e.Salary }).Reverse(),
+	)
+	expected := []string{"Eva", "Bruno", "Duarte", "Carla", "Ana", "Filipe"}
+	if got := names(es); !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCompare_Then(t *testing.T) {
+	bySalary := By(func(e employee) int { return e.Salary })
+	byName := By(func(e employee) string { return e.Name })
+	c := bySalary.Then(byName.Reverse())
+	es := employees()
+	slices.SortFunc(es, c)
+	expected := []string{"Eva", "Filipe", "Ana", "Carla", "Duarte", "Bruno"}
+	if got := names(es); !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	s := []int{9, 3, 7, 1, 8, 2, 6}
+	if got := TopK(s, 3, cmp.Compare[int]); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected the 3 smallest, got %v", got)
+	}
+	if got := TopK(s, 2, Compare[int](cmp.Compare[int]).Reverse()); !slices.Equal(got, []int{9, 8}) {
+		t.Errorf("Expected the 2 largest, got %v", got)
+	}
+	if got := TopK(s, 20, cmp.Compare[int]); len(got) != len(s) {
+		t.Errorf("Expected every element when k > len, got %v", got)
+	}
+	if got := TopK(s, 0, cmp.Compare[int]); got != nil {
+		t.Errorf("Expected nothing for k = 0, got %v", got)
+	}
+	if !slices.Equal(s, []int{9, 3, 7, 1, 8, 2, 6}) {
+		t.Error("TopK must not modify its input")
+	}
+}
+
+func randomInts(n int) []int {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := make([]int, n)
+	for i := range s {
+		s[i] = r.IntN(n)
+	}
+	return s
+}
+
+func TestParallel(t *testing.T) {
+	for _, n := range []int{0, 10, parallelThreshold, 100003} {
+		for _, workers := range []int{0, 1, 3, 8} {
+			s := randomInts(n)
+			expected := slices.Clone(s)
+			slices.Sort(expected)
+			Parallel(s, cmp.Compare[int], workers)
+			if !slices.Equal(s, expected) {
+				t.Errorf("n=%d workers=%d: the slice is not sorted", n, workers)
+			}
+		}
+	}
+}
+
+func TestExternal(t *testing.T) {
+	dir := t.TempDir()
+	type pair struct {
+		Key, Seq int
+	}
+	in := make([]pair, 10000)
+	for i, k := range randomInts(len(in)) {
+		in[i] = pair{k % 100, i}
+	}
+	e := External[pair]{Compare: By(func(p pair) int { return p.Key }), RunSize: 333, TempDir: dir}
+	var out []pair
+	err := e.Sort(slices.Values(in), func(p pair) error {
+		out = append(out, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := slices.Clone(in)
+	slices.SortStableFunc(expected, e.Compare)
+	if !slices.Equal(out, expected) {
+		t.Error("The external sort is not the stable sort of its input")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("The runs were not removed: %d files left", len(files))
+	}
+}
+
+func TestExternal_MergePasses(t *testing.T) {
+	dir := t.TempDir()
+	in := randomInts(1000)
+	e := External[int]{Compare: cmp.Compare[int], RunSize: 10, FanIn: 4, TempDir: dir}
+	var out []int
+	err := e.Sort(slices.Values(in), func(v int) error {
+		if files, _ := os.ReadDir(dir); len(files) > e.FanIn {
+			return fmt.Errorf("%d runs left for the final merge", len(files))
+		}
+		out = append(out, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := slices.Sorted(slices.Values(in))
+	if !slices.Equal(out, expected) {
+		t.Error("The multi-pass merge did not sort its input")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("The runs were not removed: %d files left", len(files))
+	}
+}
+
+func TestExternal_EmitError(t *testing.T) {
+	dir := t.TempDir()
+	errStop := errors.New("stop")
+	e := External[int]{Compare: cmp.Compare[int], RunSize: 10, TempDir: dir}
+	err := e.Sort(slices.Values(randomInts(100)), func(int) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Errorf("Expected the emit error, got %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("The runs were not removed: %d files left", len(files))
+	}
+}
+
+const benchSize = 1 << 20
+
+func BenchmarkSlicesSort(b *testing.B) {
+	src := randomInts(benchSize)
+	s := make([]int, len(src))
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		slices.Sort(s)
+	}
+}
+
+func BenchmarkSlicesSortFunc(b *testing.B) {
+	src := randomInts(benchSize)
+	s := make([]int, len(src))
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		slices.SortFunc(s, cmp.Compare[int])
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	src := randomInts(benchSize)
+	s := make([]int, len(src))
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		Parallel(s, cmp.Compare[int], 0)
+	}
+}
+
+func BenchmarkSlicesSort_TopK(b *testing.B) {
+	src := randomInts(benchSize)
+	s := make([]int, len(src))
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		slices.Sort(s)
+		_ = s[:10]
+	}
+}
+
+func BenchmarkTopK(b *testing.B) {
+	s := randomInts(benchSize)
+	for i := 0; i < b.N; i++ {
+		TopK(s, 10, cmp.Compare[int])
+	}
+}
+
+func BenchmarkStable(b *testing.B) {
+	src := randomInts(benchSize)
+	s := make([]int, len(src))
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		Stable(s, cmp.Compare[int])
+	}
+}
@@ -0,0 +1,46 @@
+package sorting
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// boundedHeap keeps the k smallest elements seen so far with the largest of
+// them on top, so it can be evicted in O(log k). It implements heap.Interface,
+// whose algorithms are another use of the template method.
+type boundedHeap[T any] struct {
+	items   []T
+	compare Compare[T]
+}
+
+func (h *boundedHeap[T]) Len() int           { return len(h.items) }
+func (h *boundedHeap[T]) Less(i, j int) bool { return h.compare(h.items[i], h.items[j]) > 0 }
+func (h *boundedHeap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *boundedHeap[T]) Push(x any)         { h.items = append(h.items, x.(T)) }
+func (h *boundedHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// TopK returns the k first elements of s in the order given by compare,
+// without sorting the whole slice. s is not modified. Use compare.Reverse()
+// to get the k largest.
+func TopK[T any](s []T, k int, compare Compare[T]) []T {
+	if k <= 0 {
+		return nil
+	}
+	h := &boundedHeap[T]{items: make([]T, 0, min(k, len(s))), compare: compare}
+	for _, v := range s {
+		if h.Len() < k {
+			heap.Push(h, v)
+			continue
+		}
+		if compare(v, h.items[0]) < 0 {
+			h.items[0] = v
+			heap.Fix(h, 0)
+		}
+	}
+	slices.SortFunc(h.items, compare)
+	return h.items
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Mergeable is a visitor whose work can be split: Fork returns a new empty
+// visitor of the same kind for a shard of items, and Merge folds the result of
+// a shard that comes after the receiver's items into the receiver.
+type Mergeable[V any] interface {
+	Visitor
+	Fork() V
+	Merge(V)
+}
+
+// shardsPerWorker keeps workers busy when some shards are slower than others.
+const shardsPerWorker = 4
+
+// VisitParallel makes v visit every item, sharding the items across a pool of
+// workers goroutines (GOMAXPROCS when workers <= 0). The shards are contiguous
+// and merged into v in item order, so the result doesn't depend on goroutine
+// scheduling and order sensitive visitors such as NamePrinter still get the
+// same result as a sequential loop.
+func VisitParallel[V Mergeable[V]](items []Visitable, v V, workers int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	shards := min(workers*shardsPerWorker, len(items))
+	if workers == 1 || shards <= 1 {
+		for _, item := range items {
+			item.Accept(v)
+		}
+		return
+	}
+	size := (len(items) + shards - 1) / shards
+	shards = (len(items) + size - 1) / size
+	workers = min(workers, shards)
+	partials := make([]V, shards)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range jobs {
+				partial := v.Fork()
+				for _, item := range items[s*size : min((s+1)*size, len(items))] {
+					item.Accept(partial)
+				}
+				partials[s] = partial
+			}
+		}()
+	}
+	for s := 0; s < shards; s++ {
+		jobs <- s
+	}
+	close(jobs)
+	wg.Wait()
+	for _, partial := range partials {
+		v.Merge(partial)
+	}
+}
+
+// Fork returns an empty PriceVisitor.
+func (pv *PriceVisitor) Fork() *PriceVisitor {
+	return &PriceVisitor{}
+}
+
+// Merge adds the sum of another shard.
+func (pv *PriceVisitor) Merge(other *PriceVisitor) {
+	pv.Sum = pv.Sum.MustAdd(other.Sum)
+}
+
+// Fork returns an empty NamePrinter.
+func (n *NamePrinter) Fork() *NamePrinter {
+	return &NamePrinter{}
+}
+
+// Merge prepends the names of a later shard, as Visit does for a single name.
+func (n *NamePrinter) Merge(other *NamePrinter) {
+	n.ProductList = other.ProductList + n.ProductList
+}
@@ -1,9 +1,13 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
 
 type ProductInfoRetriever interface {
-	GetPrice() float32
+	GetPrice() money.Money
 	GetName() string
 }
 type Visitor interface {
@@ -13,11 +17,11 @@ type Visitable interface {
 	Accept(Visitor)
 }
 type Product struct {
-	Price float32
+	Price money.Money
 	Name  string
 }
 
-func (p *Product) GetPrice() float32 {
+func (p *Product) GetPrice() money.Money {
 	return p.Price
 }
 func (p *Product) Accept(v Visitor) {
@@ -34,11 +38,11 @@ type Pasta struct {
 	Product
 }
 type PriceVisitor struct {
-	Sum float32
+	Sum money.Money
 }
 
 func (pv *PriceVisitor) Visit(p ProductInfoRetriever) {
-	pv.Sum += p.GetPrice()
+	pv.Sum = pv.Sum.MustAdd(p.GetPrice())
 }
 
 type NamePrinter struct {
@@ -53,8 +57,11 @@ type Fridge struct {
 	Product
 }
 
-func (f *Fridge) GetPrice() float32 {
-	return f.Product.Price + 20
+// fridgeSurcharge is added to the price of every fridge.
+var fridgeSurcharge = money.MustParse("20.00", "EUR")
+
+func (f *Fridge) GetPrice() money.Money {
+	return f.Product.Price.MustAdd(fridgeSurcharge)
 }
 func (f *Fridge) Accept(v Visitor) {
 	v.Visit(f)
@@ -64,19 +71,19 @@ func main() {
 	products := make([]Visitable, 3)
 	products[0] = &Rice{
 		Product: Product{
-			Price: 32.0,
+			Price: money.MustParse("32.00", "EUR"),
 			Name:  "Some rice",
 		},
 	}
 	products[1] = &Pasta{
 		Product: Product{
-			Price: 40.0,
+			Price: money.MustParse("40.00", "EUR"),
 			Name:  "Some pasta",
 		},
 	}
 	products[2] = &Fridge{
 		Product: Product{
-			Price: 50,
+			Price: money.MustParse("50.00", "EUR"),
 			Name:  "A fridge",
 		},
 	}
@@ -85,7 +92,7 @@ func main() {
 	for _, p := range products {
 		p.Accept(priceVisitor)
 	}
-	fmt.Printf("Total: %f\n", priceVisitor.Sum)
+	fmt.Printf("Total: %s\n", priceVisitor.Sum.Format(money.EnUS))
 	//Print the products list
 	nameVisitor := &NamePrinter{}
 	for _, p := range products {
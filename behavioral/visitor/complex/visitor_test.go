@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+func catalog(n int) []Visitable {
+	items := make([]Visitable, n)
+	for i := range items {
+		p := Product{Price: money.MustNew(int64(i%7+1)*100, "EUR"), Name: fmt.Sprintf("Product %d", i)}
+		switch i % 3 {
+		case 0:
+			items[i] = &Rice{Product: p}
+		case 1:
+			items[i] = &Pasta{Product: p}
+		default:
+			items[i] = &Fridge{Product: p}
+		}
+	}
+	return items
+}
+
+func TestVisitParallel(t *testing.T) {
+	items := catalog(1001)
+	sequentialPrice := &PriceVisitor{}
+	sequentialNames := &NamePrinter{}
+	for _, p := range items {
+		p.Accept(sequentialPrice)
+		p.Accept(sequentialNames)
+	}
+	for _, workers := range []int{0, 1, 3, 8, 2000} {
+		price := &PriceVisitor{}
+		VisitParallel(items, price, workers)
+		if price.Sum != sequentialPrice.Sum {
+			t.Errorf("workers=%d: expected sum %s, got %s", workers, sequentialPrice.Sum, price.Sum)
+		}
+		names := &NamePrinter{}
+		VisitParallel(items, names, workers)
+		if names.ProductList != sequentialNames.ProductList {
+			t.Errorf("workers=%d: the product list is not in the sequential order", workers)
+		}
+	}
+}
+
+func TestVisitParallel_Deterministic(t *testing.T) {
+	items := catalog(5000)
+	first := &PriceVisitor{}
+	VisitParallel(items, first, 4)
+	for i := 0; i < 20; i++ {
+		again := &PriceVisitor{}
+		VisitParallel(items, again, 4)
+		if again.Sum != first.Sum {
+			t.Fatalf("Run %d gave %s instead of %s", i, again.Sum, first.Sum)
+		}
+	}
+}
+
+func TestVisitParallel_Empty(t *testing.T) {
+	price := &PriceVisitor{}
+	VisitParallel(nil, price, 4)
+	if !price.Sum.IsZero() {
+		t.Errorf("Expected 0, got %s", price.Sum)
+	}
+}
+
+var benchItems = catalog(50000)
+
+func BenchmarkPriceVisitor_Sequential(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		priceVisitor := &PriceVisitor{}
+		for _, p := range benchItems {
+			p.Accept(priceVisitor)
+		}
+	}
+}
+
+func BenchmarkPriceVisitor_Parallel(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		VisitParallel(benchItems, &PriceVisitor{}, 0)
+	}
+}
+
+func BenchmarkNamePrinter_Sequential(b *testing.B) {
+	items := benchItems[:5000]
+	for i := 0; i < b.N; i++ {
+		nameVisitor := &NamePrinter{}
+		for _, p := range items {
+			p.Accept(nameVisitor)
+		}
+	}
+}
+
+func BenchmarkNamePrinter_Parallel(b *testing.B) {
+	items := benchItems[:5000]
+	for i := 0; i < b.N; i++ {
+		VisitParallel(items, &NamePrinter{}, 0)
+	}
+}
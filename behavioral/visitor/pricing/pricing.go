@@ -0,0 +1,121 @@
+// Package pricing turns a shopping cart into an itemized receipt. Every
+// pricing rule (taxes, surcharges, discounts, coupons) is a Visitor that walks
+// the receipt and records Adjustments, so each cent can be traced back to the
+// rule that produced it. Amounts are money.Money values, and all the amounts
+// of a cart and of its rules must be in the same currency: mixing currencies
+// panics, like adding them with money.Money.MustAdd does.
+package pricing
+
+import "github.com/antoniofmoliveira/patterns/money"
+
+// Category groups products that share the same pricing rules.
+type Category string
+
+const (
+	Food      Category = "food"
+	Appliance Category = "appliance"
+	Clothing  Category = "clothing"
+	Books     Category = "books"
+)
+
+// Item is a product put in the cart.
+type Item struct {
+	SKU       string      `json:"sku"`
+	Name      string      `json:"name"`
+	Category  Category    `json:"category"`
+	UnitPrice money.Money `json:"unit_price"`
+	Quantity  int         `json:"quantity"`
+}
+
+// Cart is what the customer is buying, along with the coupon codes entered.
+type Cart struct {
+	Items   []Item
+	Coupons []string
+}
+
+// Adjustment is a change in price made by a rule. Discounts are negative.
+type Adjustment struct {
+	Rule        string      `json:"rule"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Line is a receipt line for one cart item.
+type Line struct {
+	Item
+	Subtotal    money.Money  `json:"subtotal"`
+	Adjustments []Adjustment `json:"adjustments,omitempty"`
+	Total       money.Money  `json:"total"`
+}
+
+// Adjust records an adjustment on the line and updates its total.
+func (l *Line) Adjust(a Adjustment) {
+	if a.Amount.IsZero() {
+		return
+	}
+	l.Adjustments = append(l.Adjustments, a)
+	l.Total = l.Total.MustAdd(a.Amount)
+}
+
+// Accept lets v price the line.
+func (l *Line) Accept(v Visitor) {
+	v.VisitLine(l)
+}
+
+// Receipt is the priced cart. Adjustments holds the cart wide adjustments,
+// such as coupons, that don't belong to a single line.
+type Receipt struct {
+	Lines       []*Line      `json:"lines"`
+	Coupons     []string     `json:"coupons,omitempty"`
+	Adjustments []Adjustment `json:"adjustments,omitempty"`
+	Subtotal    money.Money  `json:"subtotal"`
+	Total       money.Money  `json:"total"`
+}
+
+// Adjust records a cart wide adjustment and updates the receipt total.
+func (r *Receipt) Adjust(a Adjustment) {
+	if a.Amount.IsZero() {
+		return
+	}
+	r.Adjustments = append(r.Adjustments, a)
+	r.Total = r.Total.MustAdd(a.Amount)
+}
+
+// Accept lets v visit every line and then the receipt itself.
+func (r *Receipt) Accept(v Visitor) {
+	for _, l := range r.Lines {
+		before := l.Total
+		l.Accept(v)
+		r.Total = r.Total.MustAdd(l.Total).MustAdd(before.Neg())
+	}
+	v.VisitReceipt(r)
+}
+
+// Visitor is a pricing rule. VisitLine is called for every line first, then
+// VisitReceipt once for the whole receipt.
+type Visitor interface {
+	VisitLine(*Line)
+	VisitReceipt(*Receipt)
+}
+
+// Visitable is anything a pricing rule can visit.
+type Visitable interface {
+	Accept(Visitor)
+}
+
+// Price builds the receipt for the cart and applies the rules in the given
+// order. Order matters: put discounts before taxes to tax the discounted
+// price.
+func Price(c Cart, rules ...Visitor) *Receipt {
+	r := &Receipt{Coupons: c.Coupons}
+	for _, it := range c.Items {
+		sub := it.UnitPrice.Times(int64(it.Quantity))
+		r.Lines = append(r.Lines, &Line{Item: it, Subtotal: sub, Total: sub})
+		r.Subtotal = r.Subtotal.MustAdd(sub)
+	}
+	r.Total = r.Subtotal
+	for _, rule := range rules {
+		r.Accept(rule)
+	}
+	return r
+}
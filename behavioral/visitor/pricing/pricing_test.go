@@ -0,0 +1,140 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+func eur(cents int64) money.Money {
+	return money.MustNew(cents, "EUR")
+}
+
+func sampleCart() Cart {
+	return Cart{
+		Items: []Item{
+			{SKU: "RICE", Name: "Some rice", Category: Food, UnitPrice: eur(320), Quantity: 3},
+			{SKU: "PASTA", Name: "Some pasta", Category: Food, UnitPrice: eur(199), Quantity: 1},
+			{SKU: "FRIDGE", Name: "A fridge", Category: Appliance, UnitPrice: eur(49999), Quantity: 1},
+		},
+		Coupons: []string{"WELCOME"},
+	}
+}
+
+func sampleRules() []Visitor {
+	return []Visitor{
+		Surcharge{Name: "delivery", Category: Appliance, PerUnit: eur(2000)},
+		BuyNGetM{SKU: "RICE", Buy: 2, Free: 1},
+		PercentageDiscount{Name: "summer sale", Category: Appliance, Off: 10 * Percent},
+		Tax{Name: "VAT", Rates: map[Category]int64{Food: 6 * Percent, Appliance: 23 * Percent}},
+		Coupon{Code: "WELCOME", Amount: eur(1000), MinTotal: eur(5000)},
+	}
+}
+
+func TestPrice(t *testing.T) {
+	r := Price(sampleCart(), sampleRules()...)
+
+	rice := r.Lines[0]
+	// 3 x 3.20 with one free = 6.40, plus 6% VAT (0.384 rounded to 0.38).
+	if rice.Total != eur(678) {
+		t.Errorf("Expected rice total 6.78, got %s", rice.Total)
+	}
+	fridge := r.Lines[2]
+	// (499.99 + 20.00) - 10% = 467.99, plus 23% VAT (107.6377 rounded to 107.64).
+	if fridge.Total != eur(57563) {
+		t.Errorf("Expected fridge total 575.63, got %s", fridge.Total)
+	}
+	if len(fridge.Adjustments) != 3 || fridge.Adjustments[0].Rule != "delivery" ||
+		fridge.Adjustments[1].Rule != "summer sale" || fridge.Adjustments[2].Rule != "VAT" {
+		t.Errorf("Fridge adjustments are not traceable to their rules: %+v", fridge.Adjustments)
+	}
+
+	var total money.Money
+	for _, l := range r.Lines {
+		total = total.MustAdd(l.Total)
+	}
+	for _, a := range r.Adjustments {
+		total = total.MustAdd(a.Amount)
+	}
+	if total != r.Total {
+		t.Errorf("Receipt total %s doesn't match the sum of its lines %s", r.Total, total)
+	}
+	if r.Total != eur(678+211+57563-1000) {
+		t.Errorf("Unexpected receipt total %s", r.Total)
+	}
+}
+
+func TestCoupon(t *testing.T) {
+	cart := Cart{Items: []Item{{SKU: "BOOK", Name: "A book", Category: Books, UnitPrice: eur(1500), Quantity: 1}}}
+	r := Price(cart, Coupon{Code: "HALF", Off: 50 * Percent})
+	if r.Total != eur(1500) {
+		t.Error("A coupon that wasn't entered must not apply")
+	}
+	cart.Coupons = []string{"HALF", "BIG"}
+	r = Price(cart, Coupon{Code: "HALF", Off: 50 * Percent}, Coupon{Code: "BIG", Amount: eur(5000)})
+	if !r.Total.IsZero() {
+		t.Errorf("Coupons must not make the total negative, got %s", r.Total)
+	}
+	if len(r.Adjustments) != 2 || r.Adjustments[1].Amount != eur(-750) {
+		t.Errorf("Unexpected coupon adjustments %+v", r.Adjustments)
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		bp  int64
+		out string
+	}{
+		{23 * Percent, "23%"}, {550, "5.5%"}, {5, "0.05%"}, {-10 * Percent, "-10%"},
+	}
+	for _, test := range tests {
+		if s := formatRate(test.bp); s != test.out {
+			t.Errorf("formatRate(%d) = %s; expected %s", test.bp, s, test.out)
+		}
+	}
+	if m := rate(eur(-250), 10*Percent); m != eur(-25) {
+		t.Errorf("Expected -0.25, got %s", m)
+	}
+	if m := rate(eur(5), 10*Percent); m != eur(1) {
+		t.Errorf("Expected 0.01 rounded half up, got %s", m)
+	}
+}
+
+func TestReceipt_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Price(sampleCart(), sampleRules()...).WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Some rice       3 x 3.20       9.60
+  buy 2 get 1   1 free         -3.20
+  VAT           6% on 6.40     0.38
+Some pasta      1 x 1.99       1.99
+  VAT           6% on 1.99     0.12
+A fridge        1 x 499.99     499.99
+  delivery      1 x 20.00      20.00
+  summer sale   10% off        -52.00
+  VAT           23% on 467.99  107.64
+Subtotal                       511.58
+coupon WELCOME  10.00 off      -10.00
+Total                          574.52 EUR
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected text receipt:\n%s", buf.String())
+	}
+}
+
+func TestReceipt_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Price(sampleCart(), sampleRules()...).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Receipt
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Total != eur(57452) || decoded.Lines[2].Adjustments[2].Amount != eur(10764) {
+		t.Errorf("The JSON receipt doesn't round trip: %s", buf.String())
+	}
+}
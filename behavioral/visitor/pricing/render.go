@@ -0,0 +1,35 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteText renders the receipt as a plain text table, with every adjustment
+// listed under the line it belongs to along with the rule that made it.
+// Amounts are plain decimals; the currency is written once, on the total.
+func (r *Receipt) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, l := range r.Lines {
+		fmt.Fprintf(tw, "%s\t%d x %s\t%s\n", l.Name, l.Quantity, l.UnitPrice.Decimal(), l.Subtotal.Decimal())
+		for _, a := range l.Adjustments {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", a.Rule, a.Description, a.Amount.Decimal())
+		}
+	}
+	fmt.Fprintf(tw, "Subtotal\t\t%s\n", r.Subtotal.Decimal())
+	for _, a := range r.Adjustments {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Rule, a.Description, a.Amount.Decimal())
+	}
+	fmt.Fprintf(tw, "Total\t\t%s %s\n", r.Total.Decimal(), r.Total.Currency().Code)
+	return tw.Flush()
+}
+
+// WriteJSON renders the receipt as indented JSON. Amounts are strings with
+// their currency code, like "10.00 EUR".
+func (r *Receipt) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+// Rates are integers in basis points, so 23 * Percent is 23% and 550 is 5.5%.
+const Percent = 100
+
+// rate returns the given rate of m, rounded half away from zero to the minor
+// unit. It panics on an overflow.
+func rate(m money.Money, bp int64) money.Money {
+	v, err := m.Scale(bp, 100*Percent, money.HalfUp)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// formatRate writes a rate in basis points as a percentage, like "5.5%".
+func formatRate(bp int64) string {
+	sign := ""
+	if bp < 0 {
+		sign, bp = "-", -bp
+	}
+	s := fmt.Sprintf("%d.%02d", bp/Percent, bp%Percent)
+	return sign + strings.TrimSuffix(strings.TrimRight(s, "0"), ".") + "%"
+}
+
+// less reports whether a < b. It panics on a currency mismatch.
+func less(a, b money.Money) bool {
+	c, err := a.Cmp(b)
+	if err != nil {
+		panic(err)
+	}
+	return c < 0
+}
+
+// Tax charges a rate per category on the current line total. Categories
+// without a rate are not taxed.
+type Tax struct {
+	Name  string
+	Rates map[Category]int64
+}
+
+func (t Tax) VisitLine(l *Line) {
+	bp, ok := t.Rates[l.Category]
+	if !ok {
+		return
+	}
+	l.Adjust(Adjustment{
+		Rule:        nameOr(t.Name, "tax"),
+		Description: fmt.Sprintf("%s on %s", formatRate(bp), l.Total.Decimal()),
+		Amount:      rate(l.Total, bp),
+	})
+}
+
+func (Tax) VisitReceipt(*Receipt) {}
+
+// Surcharge adds a fixed amount per unit to every item of a category, like
+// the delivery fee of a fridge.
+type Surcharge struct {
+	Name     string
+	Category Category
+	PerUnit  money.Money
+}
+
+func (s Surcharge) VisitLine(l *Line) {
+	if l.Category != s.Category {
+		return
+	}
+	l.Adjust(Adjustment{
+		Rule:        nameOr(s.Name, "surcharge"),
+		Description: fmt.Sprintf("%d x %s", l.Quantity, s.PerUnit.Decimal()),
+		Amount:      s.PerUnit.Times(int64(l.Quantity)),
+	})
+}
+
+func (Surcharge) VisitReceipt(*Receipt) {}
+
+// PercentageDiscount takes a rate off the current total of the lines of a
+// category, or of every line when Category is empty.
+type PercentageDiscount struct {
+	Name     string
+	Category Category
+	Off      int64
+}
+
+func (d PercentageDiscount) VisitLine(l *Line) {
+	if d.Category != "" && l.Category != d.Category {
+		return
+	}
+	l.Adjust(Adjustment{
+		Rule:        nameOr(d.Name, "discount"),
+		Description: fmt.Sprintf("%s off", formatRate(d.Off)),
+		Amount:      rate(l.Total, d.Off).Neg(),
+	})
+}
+
+func (PercentageDiscount) VisitReceipt(*Receipt) {}
+
+// BuyNGetM gives Free units for every Buy units paid of the given SKU, so
+// Buy: 2, Free: 1 is the classic "3 for 2".
+type BuyNGetM struct {
+	Name string
+	SKU  string
+	Buy  int
+	Free int
+}
+
+func (b BuyNGetM) VisitLine(l *Line) {
+	if l.SKU != b.SKU || b.Buy <= 0 || b.Free <= 0 {
+		return
+	}
+	free := l.Quantity / (b.Buy + b.Free) * b.Free
+	l.Adjust(Adjustment{
+		Rule:        nameOr(b.Name, fmt.Sprintf("buy %d get %d", b.Buy, b.Free)),
+		Description: fmt.Sprintf("%d free", free),
+		Amount:      l.UnitPrice.Times(int64(free)).Neg(),
+	})
+}
+
+func (BuyNGetM) VisitReceipt(*Receipt) {}
+
+// Coupon takes either a rate or a fixed amount off the whole receipt when its
+// code was entered and the running total reaches MinTotal. The discount never
+// makes the total negative.
+type Coupon struct {
+	Code     string
+	Off      int64
+	Amount   money.Money
+	MinTotal money.Money
+}
+
+func (Coupon) VisitLine(*Line) {}
+
+func (c Coupon) VisitReceipt(r *Receipt) {
+	if !slices.Contains(r.Coupons, c.Code) || less(r.Total, c.MinTotal) {
+		return
+	}
+	off, desc := c.Amount, fmt.Sprintf("%s off", c.Amount.Decimal())
+	if c.Off != 0 {
+		off, desc = rate(r.Total, c.Off), fmt.Sprintf("%s off %s", formatRate(c.Off), r.Total.Decimal())
+	}
+	if less(r.Total, off) {
+		off = r.Total
+	}
+	r.Adjust(Adjustment{
+		Rule:        "coupon " + c.Code,
+		Description: desc,
+		Amount:      off.Neg(),
+	})
+}
+
+func nameOr(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
@@ -0,0 +1,112 @@
+// Package document is a worked example of the tree visitor framework: a
+// document made of sections, paragraphs, text and links, with typed visitors.
+package document
+
+import "github.com/antoniofmoliveira/patterns/behavioral/visitor/tree"
+
+// Visitor has one method per node type. Each visit returns a typed result and
+// tells the walker whether to descend into the node children.
+type Visitor[R any] interface {
+	VisitDocument(*Document) (R, tree.Action)
+	VisitSection(*Section) (R, tree.Action)
+	VisitParagraph(*Paragraph) (R, tree.Action)
+	VisitText(*Text) (R, tree.Action)
+	VisitLink(*Link) (R, tree.Action)
+}
+
+// Node is any element of a document.
+type Node interface {
+	tree.Node
+	accept(dispatcher)
+}
+
+// dispatcher is the non generic side of the double dispatch: Go methods can't
+// have type parameters, so nodes call it and it forwards to a Visitor[R].
+type dispatcher interface {
+	document(*Document)
+	section(*Section)
+	paragraph(*Paragraph)
+	text(*Text)
+	link(*Link)
+}
+
+type adapter[R any] struct {
+	v      Visitor[R]
+	result R
+	action tree.Action
+}
+
+func (a *adapter[R]) document(n *Document)   { a.result, a.action = a.v.VisitDocument(n) }
+func (a *adapter[R]) section(n *Section)     { a.result, a.action = a.v.VisitSection(n) }
+func (a *adapter[R]) paragraph(n *Paragraph) { a.result, a.action = a.v.VisitParagraph(n) }
+func (a *adapter[R]) text(n *Text)           { a.result, a.action = a.v.VisitText(n) }
+func (a *adapter[R]) link(n *Link)           { a.result, a.action = a.v.VisitLink(n) }
+
+// Accept dispatches n to the matching method of v and returns its result.
+func Accept[R any](n Node, v Visitor[R]) (R, tree.Action) {
+	a := &adapter[R]{v: v}
+	n.accept(a)
+	return a.result, a.action
+}
+
+// Walk visits every node under root in the given order with v.
+func Walk[R any](root Node, order tree.Order, v Visitor[R]) []R {
+	return tree.Walk(root, order, func(n tree.Node) (R, tree.Action) {
+		return Accept(n.(Node), v)
+	})
+}
+
+// Document is the root of the tree.
+type Document struct {
+	Title    string
+	Sections []*Section
+}
+
+// Section is a titled block holding paragraphs and nested sections.
+type Section struct {
+	Title string
+	Body  []Node
+}
+
+// Paragraph is a run of text and links.
+type Paragraph struct {
+	Inlines []Node
+}
+
+// Text is plain text inside a paragraph.
+type Text struct {
+	Value string
+}
+
+// Link is a hyperlink inside a paragraph.
+type Link struct {
+	Text string
+	URL  string
+}
+
+func (d *Document) Children() []tree.Node {
+	children := make([]tree.Node, len(d.Sections))
+	for i, s := range d.Sections {
+		children[i] = s
+	}
+	return children
+}
+
+func (s *Section) Children() []tree.Node   { return nodes(s.Body) }
+func (p *Paragraph) Children() []tree.Node { return nodes(p.Inlines) }
+func (t *Text) Children() []tree.Node      { return nil }
+func (l *Link) Children() []tree.Node      { return nil }
+
+func (d *Document) accept(v dispatcher)  { v.document(d) }
+func (s *Section) accept(v dispatcher)   { v.section(s) }
+func (p *Paragraph) accept(v dispatcher) { v.paragraph(p) }
+func (t *Text) accept(v dispatcher)      { v.text(t) }
+func (l *Link) accept(v dispatcher)      { v.link(l) }
+
+func nodes(ns []Node) []tree.Node {
+	children := make([]tree.Node, len(ns))
+	for i, n := range ns {
+		children[i] = n
+	}
+	return children
+}
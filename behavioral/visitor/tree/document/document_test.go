@@ -0,0 +1,95 @@
+package document
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/antoniofmoliveira/patterns/behavioral/visitor/tree"
+)
+
+func sampleDocument() *Document {
+	return &Document{
+		Title: "Patterns",
+		Sections: []*Section{
+			{Title: "Visitor", Body: []Node{
+				&Paragraph{Inlines: []Node{
+					&Text{Value: "Separates algorithms from"},
+					&Text{Value: "the objects they work on."},
+				}},
+				&Section{Title: "Draft", Body: []Node{
+					&Paragraph{Inlines: []Node{
+						&Link{Text: "unfinished notes", URL: "https://example.com/draft"},
+					}},
+				}},
+			}},
+			{Title: "See also", Body: []Node{
+				&Paragraph{Inlines: []Node{
+					&Text{Value: "Read"},
+					&Link{Text: "the book", URL: "https://example.com/book"},
+				}},
+			}},
+		},
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	if n := CountWords(sampleDocument()); n != 13 {
+		t.Errorf("Expected 13 words, got %d", n)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	expected := []string{"https://example.com/draft", "https://example.com/book"}
+	if got := Links(sampleDocument()); !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := sampleDocument()
+	draft := d.Sections[0].Body[1].(*Section)
+	draft.Body = append(draft.Body, &Section{Title: "Open questions", Body: []Node{
+		&Paragraph{Inlines: []Node{&Text{Value: "None yet."}}},
+	}})
+	expected := `# Patterns
+
+## Visitor
+
+Separates algorithms from the objects they work on.
+
+### Draft
+
+[unfinished notes](https://example.com/draft)
+
+#### Open questions
+
+None yet.
+
+## See also
+
+Read [the book](https://example.com/book)
+`
+	if got := Render(d); got != expected {
+		t.Errorf("Unexpected rendering:\n%s", got)
+	}
+}
+
+func TestSkipSections(t *testing.T) {
+	v := SkipSections[string]{Visitor: LinkCollector{}, Titles: []string{"Draft"}}
+	var links []string
+	for _, url := range Walk(sampleDocument(), tree.BreadthFirst, v) {
+		if url != "" {
+			links = append(links, url)
+		}
+	}
+	if !slices.Equal(links, []string{"https://example.com/book"}) {
+		t.Errorf("The draft section should have been skipped, got %v", links)
+	}
+}
+
+func TestAccept(t *testing.T) {
+	n, action := Accept(Node(&Text{Value: "one two three"}), Visitor[int](WordCounter{}))
+	if n != 3 || action != tree.Continue {
+		t.Errorf("Expected 3 and Continue, got %d and %d", n, action)
+	}
+}
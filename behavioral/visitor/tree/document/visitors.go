@@ -0,0 +1,116 @@
+package document
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/antoniofmoliveira/patterns/behavioral/visitor/tree"
+)
+
+// WordCounter returns the number of words of every text and link node, and 0
+// for the structural nodes.
+type WordCounter struct{}
+
+func (WordCounter) VisitDocument(*Document) (int, tree.Action)   { return 0, tree.Continue }
+func (WordCounter) VisitSection(*Section) (int, tree.Action)     { return 0, tree.Continue }
+func (WordCounter) VisitParagraph(*Paragraph) (int, tree.Action) { return 0, tree.Continue }
+func (WordCounter) VisitText(t *Text) (int, tree.Action) {
+	return len(strings.Fields(t.Value)), tree.Continue
+}
+func (WordCounter) VisitLink(l *Link) (int, tree.Action) {
+	return len(strings.Fields(l.Text)), tree.Continue
+}
+
+// CountWords returns the number of words in the document.
+func CountWords(d *Document) int {
+	total := 0
+	for _, n := range Walk(d, tree.PreOrder, WordCounter{}) {
+		total += n
+	}
+	return total
+}
+
+// LinkCollector returns the URL of every link and an empty string for the
+// other nodes.
+type LinkCollector struct{}
+
+func (LinkCollector) VisitDocument(*Document) (string, tree.Action)   { return "", tree.Continue }
+func (LinkCollector) VisitSection(*Section) (string, tree.Action)     { return "", tree.Continue }
+func (LinkCollector) VisitParagraph(*Paragraph) (string, tree.Action) { return "", tree.Continue }
+func (LinkCollector) VisitText(*Text) (string, tree.Action)           { return "", tree.Continue }
+func (LinkCollector) VisitLink(l *Link) (string, tree.Action)         { return l.URL, tree.Continue }
+
+// Links returns the URLs linked from the document, in reading order.
+func Links(d *Document) []string {
+	return slices.DeleteFunc(Walk(d, tree.PreOrder, LinkCollector{}), func(s string) bool {
+		return s == ""
+	})
+}
+
+// Markdown renders a node and everything below it as Markdown. Depth is the
+// nesting level of the visited node, 0 for the document, and sets the heading
+// level. Documents, sections and paragraphs render their children themselves
+// and skip them in the walk.
+type Markdown struct {
+	Depth int
+}
+
+func (m Markdown) VisitDocument(d *Document) (string, tree.Action) {
+	blocks := []string{m.heading(d.Title)}
+	for _, s := range d.Sections {
+		blocks = append(blocks, m.child(s))
+	}
+	return strings.Join(blocks, "\n\n"), tree.SkipChildren
+}
+func (m Markdown) VisitSection(s *Section) (string, tree.Action) {
+	blocks := []string{m.heading(s.Title)}
+	for _, n := range s.Body {
+		blocks = append(blocks, m.child(n))
+	}
+	return strings.Join(blocks, "\n\n"), tree.SkipChildren
+}
+func (m Markdown) VisitParagraph(p *Paragraph) (string, tree.Action) {
+	parts := make([]string, len(p.Inlines))
+	for i, n := range p.Inlines {
+		parts[i], _ = Accept(n, m)
+	}
+	return strings.Join(parts, " "), tree.SkipChildren
+}
+func (Markdown) VisitText(t *Text) (string, tree.Action) {
+	return t.Value, tree.Continue
+}
+func (Markdown) VisitLink(l *Link) (string, tree.Action) {
+	return fmt.Sprintf("[%s](%s)", l.Text, l.URL), tree.Continue
+}
+
+func (m Markdown) heading(title string) string {
+	return strings.Repeat("#", m.Depth+1) + " " + title
+}
+
+func (m Markdown) child(n Node) string {
+	s, _ := Accept(n, Markdown{Depth: m.Depth + 1})
+	return s
+}
+
+// Render returns the document as Markdown.
+func Render(d *Document) string {
+	s, _ := Accept(Node(d), Markdown{})
+	return s + "\n"
+}
+
+// SkipSections wraps a visitor so that sections with one of the given titles,
+// and everything below them, are left out of the walk. The skipped section
+// itself still yields the zero value of R.
+type SkipSections[R any] struct {
+	Visitor[R]
+	Titles []string
+}
+
+func (s SkipSections[R]) VisitSection(n *Section) (R, tree.Action) {
+	if slices.Contains(s.Titles, n.Title) {
+		var zero R
+		return zero, tree.SkipChildren
+	}
+	return s.Visitor.VisitSection(n)
+}
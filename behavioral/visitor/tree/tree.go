@@ -0,0 +1,116 @@
+// Package tree is a small generic visitor framework: it walks any tree of
+// Nodes in a configurable order and collects the typed result each visit
+// returns. Node types provide double dispatch on top of it, see the document
+// package for a worked example.
+package tree
+
+// Action tells the walker how to continue after a node was visited.
+type Action int
+
+const (
+	// Continue visits the children of the node and then the rest of the tree.
+	Continue Action = iota
+	// SkipChildren does not descend into the children of the node. It has no
+	// effect in PostOrder, where the children were already visited.
+	SkipChildren
+	// Stop ends the walk. The result of the current visit is kept.
+	Stop
+)
+
+// Order is the order in which Walk visits the nodes of a tree.
+type Order int
+
+const (
+	// PreOrder visits a node before its children, depth first.
+	PreOrder Order = iota
+	// PostOrder visits a node after its children, depth first.
+	PostOrder
+	// BreadthFirst visits the tree level by level.
+	BreadthFirst
+)
+
+func (o Order) String() string {
+	switch o {
+	case PreOrder:
+		return "pre-order"
+	case PostOrder:
+		return "post-order"
+	case BreadthFirst:
+		return "breadth-first"
+	}
+	return "unknown"
+}
+
+// Node is an element of a tree. Leaves return no children.
+type Node interface {
+	Children() []Node
+}
+
+// VisitFunc visits a single node and returns its result and what to do next.
+type VisitFunc[R any] func(Node) (R, Action)
+
+// Walk traverses the tree rooted at root in the given order, calling visit for
+// every node reached, and returns the results in visiting order.
+func Walk[R any](root Node, order Order, visit VisitFunc[R]) []R {
+	if root == nil {
+		return nil
+	}
+	w := &walker[R]{visit: visit}
+	switch order {
+	case PostOrder:
+		w.post(root)
+	case BreadthFirst:
+		w.breadth(root)
+	default:
+		w.pre(root)
+	}
+	return w.results
+}
+
+type walker[R any] struct {
+	visit   VisitFunc[R]
+	results []R
+	stopped bool
+}
+
+func (w *walker[R]) call(n Node) Action {
+	r, a := w.visit(n)
+	w.results = append(w.results, r)
+	if a == Stop {
+		w.stopped = true
+	}
+	return a
+}
+
+func (w *walker[R]) pre(n Node) {
+	if w.call(n) != Continue {
+		return
+	}
+	for _, c := range n.Children() {
+		if w.stopped {
+			return
+		}
+		w.pre(c)
+	}
+}
+
+func (w *walker[R]) post(n Node) {
+	for _, c := range n.Children() {
+		w.post(c)
+		if w.stopped {
+			return
+		}
+	}
+	w.call(n)
+}
+
+func (w *walker[R]) breadth(root Node) {
+	queue := []Node{root}
+	for len(queue) > 0 && !w.stopped {
+		n := queue[0]
+		queue = queue[1:]
+		if w.call(n) == Continue {
+			queue = append(queue, n.Children()...)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tree
+
+import (
+	"slices"
+	"testing"
+)
+
+type intNode struct {
+	value    int
+	children []*intNode
+}
+
+func (n *intNode) Children() []Node {
+	children := make([]Node, len(n.children))
+	for i, c := range n.children {
+		children[i] = c
+	}
+	return children
+}
+
+// sampleTree has 1 as root, 2 and 3 as its children, 4 and 5 below 2 and 6
+// below 3.
+func sampleTree() *intNode {
+	return &intNode{1, []*intNode{
+		{2, []*intNode{{4, nil}, {5, nil}}},
+		{3, []*intNode{{6, nil}}},
+	}}
+}
+
+func values(skip, stop int) VisitFunc[int] {
+	return func(n Node) (int, Action) {
+		v := n.(*intNode).value
+		switch v {
+		case skip:
+			return v, SkipChildren
+		case stop:
+			return v, Stop
+		}
+		return v, Continue
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		order    Order
+		skip     int
+		stop     int
+		expected []int
+	}{
+		{PreOrder, 0, 0, []int{1, 2, 4, 5, 3, 6}},
+		{PostOrder, 0, 0, []int{4, 5, 2, 6, 3, 1}},
+		{BreadthFirst, 0, 0, []int{1, 2, 3, 4, 5, 6}},
+		{PreOrder, 2, 0, []int{1, 2, 3, 6}},
+		{PostOrder, 2, 0, []int{4, 5, 2, 6, 3, 1}},
+		{BreadthFirst, 2, 0, []int{1, 2, 3, 6}},
+		{PreOrder, 0, 5, []int{1, 2, 4, 5}},
+		{PostOrder, 0, 2, []int{4, 5, 2}},
+		{BreadthFirst, 0, 3, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		got := Walk(sampleTree(), test.order, values(test.skip, test.stop))
+		if !slices.Equal(got, test.expected) {
+			t.Errorf("%s skip=%d stop=%d: expected %v, got %v",
+				test.order, test.skip, test.stop, test.expected, got)
+		}
+	}
+}
+
+func TestWalk_NilRoot(t *testing.T) {
+	if got := Walk(nil, PreOrder, values(0, 0)); got != nil {
+		t.Errorf("Expected no results for a nil root, got %v", got)
+	}
+}
@@ -57,9 +57,9 @@ func (m *MessageB) Print() {
 type MsgFieldVisitorPrinter struct{}
 
 func (mf *MsgFieldVisitorPrinter) VisitA(m *MessageA) {
-	fmt.Printf(m.Msg)
+	fmt.Print(m.Msg)
 }
 
 func (mf *MsgFieldVisitorPrinter) VisitB(m *MessageB) {
-	fmt.Printf(m.Msg)
+	fmt.Print(m.Msg)
 }
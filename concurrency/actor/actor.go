@@ -0,0 +1,217 @@
+// Package actor generalizes the goroutine of channel_singleton: an actor is a
+// goroutine owning some state, which it only changes in reaction to the typed
+// messages of its mailbox. It adds request/reply, bounded mailboxes with a
+// backpressure policy, restart on panic and graceful stop.
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrStopped is returned when sending to an actor that was stopped or
+	// failed.
+	ErrStopped = errors.New("actor: stopped")
+	// ErrMailboxFull is returned by Send when the mailbox is full and the
+	// policy is Fail.
+	ErrMailboxFull = errors.New("actor: mailbox full")
+	// ErrTooManyRestarts is reported by Err when the actor panicked more
+	// times than allowed and gave up.
+	ErrTooManyRestarts = errors.New("actor: too many restarts")
+)
+
+// Policy is what Send does when the mailbox is full.
+type Policy int
+
+const (
+	// Block waits for room in the mailbox or for the context to be done.
+	Block Policy = iota
+	// Drop discards the message silently. Dropped messages are counted in
+	// Stats.
+	Drop
+	// Fail returns ErrMailboxFull.
+	Fail
+)
+
+// Handler handles one message. It is only ever called from the actor
+// goroutine, so it can use the state it closes over without locking.
+type Handler[M any] func(M)
+
+// Options configures an actor. The zero value is an unbuffered mailbox with
+// the Block policy and no restarts.
+type Options struct {
+	// MailboxSize is the number of messages waiting to be handled.
+	MailboxSize int
+	Overflow    Policy
+	// MaxRestarts is how many times the behavior is recreated after a panic
+	// before the actor gives up. A negative value means no limit.
+	MaxRestarts int
+	// OnPanic is called with every recovered panic.
+	OnPanic func(recovered any)
+}
+
+// Stats are counters about an actor.
+type Stats struct {
+	Processed uint64
+	Dropped   uint64
+	Restarts  uint64
+}
+
+// Actor runs a behavior in its own goroutine and feeds it the messages of
+// its mailbox, one at a time.
+type Actor[M any] struct {
+	opts        Options
+	newBehavior func() Handler[M]
+	mailbox     chan M
+
+	mu       sync.RWMutex
+	stopped  bool
+	stopping chan struct{}
+	senders  sync.WaitGroup
+	done     chan struct{}
+	err      error
+
+	processed, dropped, restarts atomic.Uint64
+}
+
+// Spawn starts an actor. newBehavior creates the handler and its state; it
+// is called again to start from a clean state after every recovered panic.
+func Spawn[M any](newBehavior func() Handler[M], opts Options) *Actor[M] {
+	a := &Actor[M]{
+		opts:        opts,
+		newBehavior: newBehavior,
+		mailbox:     make(chan M, max(opts.MailboxSize, 0)),
+		stopping:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Send puts m in the mailbox, following the overflow policy when it is full.
+// ctx bounds how long the Block policy waits, and a blocked Send fails with
+// ErrStopped as soon as the actor is stopping.
+func (a *Actor[M]) Send(ctx context.Context, m M) error {
+	a.mu.RLock()
+	if a.stopped {
+		a.mu.RUnlock()
+		return ErrStopped
+	}
+	a.senders.Add(1)
+	a.mu.RUnlock()
+	defer a.senders.Done()
+	select {
+	case a.mailbox <- m:
+		return nil
+	default:
+	}
+	switch a.opts.Overflow {
+	case Drop:
+		a.dropped.Add(1)
+		return nil
+	case Fail:
+		return ErrMailboxFull
+	}
+	select {
+	case a.mailbox <- m:
+		return nil
+	case <-a.stopping:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops accepting messages, lets the actor handle the ones already in
+// its mailbox and waits for it to finish, or for ctx to be done.
+func (a *Actor[M]) Stop(ctx context.Context) error {
+	a.close(nil)
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done is closed when the actor goroutine has finished.
+func (a *Actor[M]) Done() <-chan struct{} {
+	return a.done
+}
+
+// Err returns why the actor finished on its own, or nil.
+func (a *Actor[M]) Err() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.err
+}
+
+// Stats returns the actor counters.
+func (a *Actor[M]) Stats() Stats {
+	return Stats{
+		Processed: a.processed.Load(),
+		Dropped:   a.dropped.Load(),
+		Restarts:  a.restarts.Load(),
+	}
+}
+
+// close rejects new messages, wakes up the blocked senders and closes the
+// mailbox once every sender in flight has returned, which ends the run loop
+// after the pending messages are handled. It never blocks: the lock is only
+// held by short critical sections. err, when not nil, is kept as the reason
+// the actor finished.
+func (a *Actor[M]) close(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil && a.err == nil {
+		a.err = err
+	}
+	if !a.stopped {
+		a.stopped = true
+		close(a.stopping)
+		go func() {
+			a.senders.Wait()
+			close(a.mailbox)
+		}()
+	}
+}
+
+func (a *Actor[M]) run() {
+	defer close(a.done)
+	handle := a.newBehavior()
+	failed := false
+	for m := range a.mailbox {
+		if failed {
+			a.dropped.Add(1)
+			continue
+		}
+		r := a.handle(handle, m)
+		if r == nil {
+			continue
+		}
+		if a.opts.OnPanic != nil {
+			a.opts.OnPanic(r)
+		}
+		if a.opts.MaxRestarts >= 0 && a.restarts.Load() >= uint64(a.opts.MaxRestarts) {
+			failed = true
+			a.close(fmt.Errorf("%w: %v", ErrTooManyRestarts, r))
+			continue
+		}
+		a.restarts.Add(1)
+		handle = a.newBehavior()
+	}
+}
+
+// handle calls h and returns the recovered panic value, if any.
+func (a *Actor[M]) handle(h Handler[M], m M) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	h(m)
+	a.processed.Add(1)
+	return nil
+}
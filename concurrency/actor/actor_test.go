@@ -0,0 +1,206 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// counterMsg is the message of a counter actor, the equivalent of the addCh
+// and getCountCh channels of channel_singleton.
+type counterMsg struct {
+	add   int
+	reply func(int)
+	panic bool
+	wait  chan struct{}
+}
+
+func newCounter() Handler[counterMsg] {
+	count := 0
+	return func(m counterMsg) {
+		if m.wait != nil {
+			<-m.wait
+		}
+		if m.panic {
+			panic("boom")
+		}
+		count += m.add
+		if m.reply != nil {
+			m.reply(count)
+		}
+	}
+}
+
+func getCount(a *Actor[counterMsg]) (int, error) {
+	return AskTimeout(a, time.Second, func(reply func(int)) counterMsg {
+		return counterMsg{reply: reply}
+	})
+}
+
+func TestActor_AskConcurrent(t *testing.T) {
+	a := Spawn(newCounter, Options{MailboxSize: 16})
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := a.Send(ctx, counterMsg{add: 1}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, err := getCount(a); err != nil || n != 100 {
+		t.Errorf("Expected 100, got %d, %v", n, err)
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Send(ctx, counterMsg{add: 1}); !errors.Is(err, ErrStopped) {
+		t.Errorf("Expected ErrStopped, got %v", err)
+	}
+}
+
+func TestActor_AskTimeout(t *testing.T) {
+	a := Spawn(func() Handler[counterMsg] {
+		return func(counterMsg) {} // never replies
+	}, Options{MailboxSize: 1})
+	defer a.Stop(context.Background())
+	_, err := getCountWithin(a, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func getCountWithin(a *Actor[counterMsg], d time.Duration) (int, error) {
+	return AskTimeout(a, d, func(reply func(int)) counterMsg {
+		return counterMsg{reply: reply}
+	})
+}
+
+func TestActor_StopDrainsMailbox(t *testing.T) {
+	wait := make(chan struct{})
+	var handled []int
+	a := Spawn(func() Handler[int] {
+		return func(n int) {
+			if n == 0 {
+				<-wait
+			}
+			handled = append(handled, n)
+		}
+	}, Options{MailboxSize: 10})
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		a.Send(ctx, i)
+	}
+	stopped := make(chan error)
+	go func() { stopped <- a.Stop(ctx) }()
+	close(wait)
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if len(handled) != 5 {
+		t.Errorf("Expected the 5 pending messages to be handled, got %v", handled)
+	}
+}
+
+func TestActor_Backpressure(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		policy  Policy
+		err     error
+		dropped uint64
+	}{
+		{Drop, nil, 1},
+		{Fail, ErrMailboxFull, 0},
+		{Block, context.DeadlineExceeded, 0},
+	}
+	for _, test := range tests {
+		wait := make(chan struct{})
+		a := Spawn(newCounter, Options{MailboxSize: 1, Overflow: test.policy})
+		a.Send(ctx, counterMsg{wait: wait}) // keeps the actor busy
+		time.Sleep(10 * time.Millisecond)
+		a.Send(ctx, counterMsg{add: 1}) // fills the mailbox
+
+		tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		err := a.Send(tctx, counterMsg{add: 1})
+		cancel()
+		if !errors.Is(err, test.err) {
+			t.Errorf("Policy %d: expected %v, got %v", test.policy, test.err, err)
+		}
+		if d := a.Stats().Dropped; d != test.dropped {
+			t.Errorf("Policy %d: expected %d dropped, got %d", test.policy, test.dropped, d)
+		}
+		close(wait)
+		a.Stop(ctx)
+	}
+}
+
+func TestActor_StopWithFullMailbox(t *testing.T) {
+	ctx := context.Background()
+	wait := make(chan struct{})
+	a := Spawn(newCounter, Options{MailboxSize: 1})
+	a.Send(ctx, counterMsg{wait: wait}) // keeps the actor busy
+	time.Sleep(10 * time.Millisecond)
+	a.Send(ctx, counterMsg{add: 1}) // fills the mailbox
+
+	blocked := make(chan error)
+	go func() { blocked <- a.Send(ctx, counterMsg{add: 1}) }()
+	time.Sleep(10 * time.Millisecond)
+
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- a.Stop(tctx) }()
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a timeout while draining, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop didn't return when its context ended")
+	}
+	select {
+	case err := <-blocked:
+		if !errors.Is(err, ErrStopped) {
+			t.Errorf("Expected the blocked Send to fail with ErrStopped, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The blocked Send was not released by Stop")
+	}
+	close(wait)
+	if err := a.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestActor_RestartOnPanic(t *testing.T) {
+	var panics []any
+	a := Spawn(newCounter, Options{MailboxSize: 4, MaxRestarts: 1, OnPanic: func(r any) {
+		panics = append(panics, r)
+	}})
+	ctx := context.Background()
+	a.Send(ctx, counterMsg{add: 5})
+	a.Send(ctx, counterMsg{panic: true})
+	if n, err := getCount(a); err != nil || n != 0 {
+		t.Errorf("Expected the state to restart from 0, got %d, %v", n, err)
+	}
+	if s := a.Stats(); s.Restarts != 1 {
+		t.Errorf("Expected 1 restart, got %d", s.Restarts)
+	}
+
+	a.Send(ctx, counterMsg{panic: true})
+	<-a.Done()
+	if !errors.Is(a.Err(), ErrTooManyRestarts) {
+		t.Errorf("Expected ErrTooManyRestarts, got %v", a.Err())
+	}
+	if _, err := getCount(a); !errors.Is(err, ErrStopped) {
+		t.Errorf("Expected ErrStopped after giving up, got %v", err)
+	}
+	if len(panics) != 2 {
+		t.Errorf("Expected 2 panics reported, got %v", panics)
+	}
+}
@@ -0,0 +1,44 @@
+package actor
+
+import (
+	"context"
+	"time"
+)
+
+// Ask sends a request to a and waits for its reply. build makes the message
+// from the function the actor must call to reply; only the first reply is
+// kept. Ask fails with ctx.Err() if no reply arrives before ctx is done, and
+// with ErrStopped if the actor finishes without replying.
+func Ask[M, R any](ctx context.Context, a *Actor[M], build func(reply func(R)) M) (R, error) {
+	var zero R
+	res := make(chan R, 1)
+	reply := func(r R) {
+		select {
+		case res <- r:
+		default:
+		}
+	}
+	if err := a.Send(ctx, build(reply)); err != nil {
+		return zero, err
+	}
+	select {
+	case r := <-res:
+		return r, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-a.Done():
+		select {
+		case r := <-res:
+			return r, nil
+		default:
+			return zero, ErrStopped
+		}
+	}
+}
+
+// AskTimeout is Ask with a timeout instead of a context.
+func AskTimeout[M, R any](a *Actor[M], timeout time.Duration, build func(reply func(R)) M) (R, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return Ask(ctx, a, build)
+}
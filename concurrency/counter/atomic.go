@@ -0,0 +1,42 @@
+package counter
+
+import "sync/atomic"
+
+// Atomic is a Counter backed by a single atomic integer.
+type Atomic struct {
+	count   atomic.Int64
+	stopped atomic.Bool
+}
+
+// NewAtomic returns an Atomic counter.
+func NewAtomic() *Atomic {
+	return &Atomic{}
+}
+
+func (a *Atomic) AddOne() error {
+	return a.Add(1)
+}
+
+// Add adds n to the count. An Add racing with Stop may still land, but it
+// can't be observed since Count fails once stopped.
+func (a *Atomic) Add(n int64) error {
+	if a.stopped.Load() {
+		return ErrStopped
+	}
+	a.count.Add(n)
+	return nil
+}
+
+func (a *Atomic) Count() (int64, error) {
+	if a.stopped.Load() {
+		return 0, ErrStopped
+	}
+	return a.count.Load(), nil
+}
+
+func (a *Atomic) Stop() error {
+	if !a.stopped.CompareAndSwap(false, true) {
+		return ErrStopped
+	}
+	return nil
+}
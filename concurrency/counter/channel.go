@@ -0,0 +1,72 @@
+package counter
+
+import "sync"
+
+// Channel is a Counter owned by a single goroutine, like channel_singleton:
+// the count is only touched by that goroutine, which serves the add and
+// count requests it receives. Stopping closes a done channel rather than the
+// request channels, so late callers get ErrStopped instead of a panic.
+type Channel struct {
+	addCh   chan int64
+	countCh chan chan int64
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewChannel starts the goroutine owning the count and returns the counter.
+func NewChannel() *Channel {
+	c := &Channel{
+		addCh:   make(chan int64),
+		countCh: make(chan chan int64),
+		done:    make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+func (c *Channel) loop() {
+	var count int64
+	for {
+		select {
+		case n := <-c.addCh:
+			count += n
+		case ch := <-c.countCh:
+			ch <- count
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Channel) AddOne() error {
+	return c.Add(1)
+}
+
+func (c *Channel) Add(n int64) error {
+	select {
+	case c.addCh <- n:
+		return nil
+	case <-c.done:
+		return ErrStopped
+	}
+}
+
+func (c *Channel) Count() (int64, error) {
+	// Buffered so the owner never blocks if the caller gave up.
+	res := make(chan int64, 1)
+	select {
+	case c.countCh <- res:
+		return <-res, nil
+	case <-c.done:
+		return 0, ErrStopped
+	}
+}
+
+func (c *Channel) Stop() error {
+	err := ErrStopped
+	c.once.Do(func() {
+		close(c.done)
+		err = nil
+	})
+	return err
+}
@@ -0,0 +1,18 @@
+// Package counter has several implementations of the same concurrent
+// counter, from the mutex and channel singletons of this repository to
+// atomic and sharded ones, so their throughput can be compared.
+package counter
+
+import "errors"
+
+// ErrStopped is returned by every operation on a stopped counter.
+var ErrStopped = errors.New("counter: stopped")
+
+// Counter is a counter safe for concurrent use. Once stopped, every call,
+// including Stop itself, returns ErrStopped.
+type Counter interface {
+	AddOne() error
+	Add(n int64) error
+	Count() (int64, error)
+	Stop() error
+}
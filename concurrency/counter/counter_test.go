@@ -0,0 +1,121 @@
+package counter
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+var implementations = []struct {
+	name string
+	new  func() Counter
+}{
+	{"mutex", func() Counter { return NewMutex() }},
+	{"channel", func() Counter { return NewChannel() }},
+	{"atomic", func() Counter { return NewAtomic() }},
+	{"sharded", func() Counter { return NewSharded(0) }},
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			c := impl.new()
+			const goroutines, adds = 50, 200
+			var wg sync.WaitGroup
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < adds; j++ {
+						if err := c.AddOne(); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if err := c.Add(-10); err != nil {
+				t.Fatal(err)
+			}
+			n, err := c.Count()
+			if err != nil || n != goroutines*adds-10 {
+				t.Errorf("Expected %d, got %d, %v", goroutines*adds-10, n, err)
+			}
+			if err := c.Stop(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCounter_Stop(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			c := impl.new()
+			c.AddOne()
+
+			// Stopping while other goroutines are adding must not panic.
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						if err := c.AddOne(); err != nil && !errors.Is(err, ErrStopped) {
+							t.Error(err)
+						}
+					}
+				}()
+			}
+			if err := c.Stop(); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+
+			if err := c.AddOne(); !errors.Is(err, ErrStopped) {
+				t.Errorf("AddOne: expected ErrStopped, got %v", err)
+			}
+			if _, err := c.Count(); !errors.Is(err, ErrStopped) {
+				t.Errorf("Count: expected ErrStopped, got %v", err)
+			}
+			if err := c.Stop(); !errors.Is(err, ErrStopped) {
+				t.Errorf("Stop: expected ErrStopped, got %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkCounter_AddOne(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			c := impl.new()
+			defer c.Stop()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.AddOne()
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkCounter_Mixed(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			c := impl.new()
+			defer c.Stop()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if i%10 == 0 {
+						c.Count()
+					} else {
+						c.AddOne()
+					}
+					i++
+				}
+			})
+		})
+	}
+}
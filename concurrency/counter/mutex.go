@@ -0,0 +1,48 @@
+package counter
+
+import "sync"
+
+// Mutex is a Counter guarded by a sync.RWMutex, like mutex_singleton.
+type Mutex struct {
+	mu      sync.RWMutex
+	count   int64
+	stopped bool
+}
+
+// NewMutex returns a Mutex counter.
+func NewMutex() *Mutex {
+	return &Mutex{}
+}
+
+func (m *Mutex) AddOne() error {
+	return m.Add(1)
+}
+
+func (m *Mutex) Add(n int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return ErrStopped
+	}
+	m.count += n
+	return nil
+}
+
+func (m *Mutex) Count() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.stopped {
+		return 0, ErrStopped
+	}
+	return m.count, nil
+}
+
+func (m *Mutex) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return ErrStopped
+	}
+	m.stopped = true
+	return nil
+}
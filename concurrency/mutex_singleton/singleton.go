@@ -1,40 +1,25 @@
 package mutexsingleton
 
-import "sync"
+import "github.com/antoniofmoliveira/patterns/concurrency/counter"
 
-// This class definition defines a singleton struct in Go, which is a design pattern that restricts the instantiation of a class to a single instance. Here's a brief explanation of each class method:
-// * `GetInstance() *singleton`: Returns a pointer to the single instance of the singleton struct.
-// * `(s *singleton) AddOne()`: Increments the `count` field of the singleton instance while acquiring a lock to ensure thread safety.
-// * `(s *singleton) GetCount() int`: Returns the current value of the `count` field of the singleton instance while acquiring a read lock to ensure thread safety.
-// Note that the `sync.RWMutex` field is used to provide thread-safe access to the `count` field, allowing multiple readers to access the field simultaneously while preventing writers from accessing it until all readers have finished.
+// singleton is the only counter of the program. The counting itself, guarded
+// by a sync.RWMutex, lives in counter.Mutex.
 type singleton struct {
-	count int
-	sync.RWMutex
+	*counter.Mutex
 }
 
 // The single instance of the singleton struct.
-var instance singleton
+var instance = singleton{counter.NewMutex()}
 
-// GetInstance returns the single instance of the singleton struct, which is
-// initialized the first time this function is called. Subsequent calls return
-// the same instance. This ensures thread safety by preventing multiple goroutines
-// from creating multiple instances of the singleton struct.
+// GetInstance returns the single instance of the singleton struct. The
+// instance is created when the package is initialized, so every goroutine
+// gets the same one.
 func GetInstance() *singleton {
 	return &instance
 }
 
-// AddOne increments the `count` field of the singleton instance while acquiring
-// a lock to ensure thread safety.
-func (s *singleton) AddOne() {
-	s.Lock()
-	defer s.Unlock()
-	s.count++
-}
-
-// GetCount returns the current value of the `count` field of the singleton instance
-// while acquiring a read lock to ensure thread safety.
+// GetCount returns the current count, or 0 once the counter was stopped.
 func (s *singleton) GetCount() int {
-	s.RLock()
-	defer s.RUnlock()
-	return s.count
+	n, _ := s.Count()
+	return int(n)
 }
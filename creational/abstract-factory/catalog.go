@@ -0,0 +1,309 @@
+package abstract_factory
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind tells which interfaces the models of a family implement besides
+// Vehicle.
+type Kind string
+
+const (
+	KindVehicle   Kind = "vehicle"
+	KindCar       Kind = "car"
+	KindMotorbike Kind = "motorbike"
+)
+
+// Model is a vehicle described by data instead of a Go type. Type is the id
+// passed to GetVehicle; for motorbikes it is also the motorbike type.
+type Model struct {
+	Type   int    `json:"type"`
+	Name   string `json:"name"`
+	Wheels int    `json:"wheels"`
+	Seats  int    `json:"seats"`
+	Doors  int    `json:"doors,omitempty"`
+}
+
+// Family is a vehicle factory described by data: its id, as passed to
+// BuildFactory, and its models.
+type Family struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Kind   Kind    `json:"kind"`
+	Models []Model `json:"models"`
+}
+
+// CatalogVehicle is a vehicle built from a Model.
+type CatalogVehicle struct {
+	Model Model
+}
+
+func (v *CatalogVehicle) NumWheels() int { return v.Model.Wheels }
+func (v *CatalogVehicle) NumSeats() int  { return v.Model.Seats }
+
+// CatalogCar is a vehicle of a car family.
+type CatalogCar struct {
+	CatalogVehicle
+}
+
+func (c *CatalogCar) NumDoors() int { return c.Model.Doors }
+
+// CatalogMotorbike is a vehicle of a motorbike family.
+type CatalogMotorbike struct {
+	CatalogVehicle
+}
+
+func (m *CatalogMotorbike) GetMotorbikeType() int { return m.Model.Type }
+
+// New returns a new vehicle of the model, implementing Car or Motorbike
+// according to kind.
+func (m Model) New(kind Kind) Vehicle {
+	switch kind {
+	case KindCar:
+		return &CatalogCar{CatalogVehicle{m}}
+	case KindMotorbike:
+		return &CatalogMotorbike{CatalogVehicle{m}}
+	}
+	return &CatalogVehicle{m}
+}
+
+// validate returns every problem of the family, so a catalogue file can be
+// fixed in one go.
+func (f Family) validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("family %d %q: "+format, append([]any{f.ID, f.Name}, args...)...))
+	}
+	if f.ID <= 0 {
+		fail("id must be positive")
+	}
+	if f.Name == "" {
+		fail("name is required")
+	}
+	if f.Kind != KindVehicle && f.Kind != KindCar && f.Kind != KindMotorbike {
+		fail("unknown kind %q", f.Kind)
+	}
+	if len(f.Models) == 0 {
+		fail("no models")
+	}
+	types := make(map[int]bool)
+	for _, m := range f.Models {
+		model := func(format string, args ...any) {
+			fail("model %d %q: "+format, append([]any{m.Type, m.Name}, args...)...)
+		}
+		if m.Type <= 0 {
+			model("type must be positive")
+		} else if types[m.Type] {
+			model("duplicate type")
+		}
+		types[m.Type] = true
+		if m.Name == "" {
+			model("name is required")
+		}
+		if m.Wheels <= 0 {
+			model("wheels must be positive")
+		}
+		if m.Seats <= 0 {
+			model("seats must be positive")
+		}
+		if f.Kind == KindCar && m.Doors <= 0 {
+			model("a car needs doors")
+		}
+		if f.Kind != KindCar && m.Doors != 0 {
+			model("only cars have doors")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Catalog holds vehicle families loaded from data. It is safe for
+// concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	families map[int]Family
+}
+
+// NewCatalog validates the families and returns a catalog holding them.
+func NewCatalog(families ...Family) (*Catalog, error) {
+	c := &Catalog{families: make(map[int]Family)}
+	var errs []error
+	for _, f := range families {
+		errs = append(errs, c.Register(f))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Register validates a family and adds it to the catalog, so families such
+// as trucks or buses can be added at runtime. Ids and names must be unique.
+func (c *Catalog) Register(f Family) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	f.Models = slices.Clone(f.Models)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, other := range c.families {
+		if other.ID == f.ID {
+			return fmt.Errorf("family %d %q: id already used by %q", f.ID, f.Name, other.Name)
+		}
+		if other.Name == f.Name {
+			return fmt.Errorf("family %d %q: name already used by family %d", f.ID, f.Name, other.ID)
+		}
+	}
+	c.families[f.ID] = f
+	return nil
+}
+
+// Families returns the families of the catalog sorted by id.
+func (c *Catalog) Families() []Family {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]Family, 0, len(c.families))
+	for _, id := range slices.Sorted(maps.Keys(c.families)) {
+		f := c.families[id]
+		f.Models = slices.Clone(f.Models)
+		out = append(out, f)
+	}
+	return out
+}
+
+// Factory returns the factory of the family with the given id.
+func (c *Catalog) Factory(id int) (VehicleFactory, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.families[id]; !ok {
+		return nil, fmt.Errorf("factory with id %d not recognized", id)
+	}
+	return &catalogFactory{catalog: c, id: id}, nil
+}
+
+// FactoryByName returns the factory of the family with the given name.
+func (c *Catalog) FactoryByName(name string) (VehicleFactory, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for id, f := range c.families {
+		if f.Name == name {
+			return &catalogFactory{catalog: c, id: id}, nil
+		}
+	}
+	return nil, fmt.Errorf("factory %q not recognized", name)
+}
+
+type catalogFactory struct {
+	catalog *Catalog
+	id      int
+}
+
+func (f *catalogFactory) GetVehicle(v int) (Vehicle, error) {
+	f.catalog.mu.RLock()
+	defer f.catalog.mu.RUnlock()
+	family := f.catalog.families[f.id]
+	for _, m := range family.Models {
+		if m.Type == v {
+			return m.New(family.Kind), nil
+		}
+	}
+	return nil, fmt.Errorf("vehicle of type %d not recognized", v)
+}
+
+// LoadCatalogJSON reads a catalogue of the form
+//
+//	{"families": [{"id": 3, "name": "truck", "kind": "vehicle", "models": [...]}]}
+//
+// Unknown fields are rejected.
+func LoadCatalogJSON(r io.Reader) (*Catalog, error) {
+	var doc struct {
+		Families []Family `json:"families"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	return NewCatalog(doc.Families...)
+}
+
+// csvHeader is the header expected by LoadCatalogCSV. Every row is a model;
+// the rows of a family must agree on its name and kind.
+var csvHeader = []string{"family_id", "family", "kind", "type", "model", "wheels", "seats", "doors"}
+
+// LoadCatalogCSV reads a catalogue with one model per row, see csvHeader.
+// The doors column can be left empty for vehicles other than cars.
+func LoadCatalogCSV(r io.Reader) (*Catalog, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	if !slices.Equal(header, csvHeader) {
+		return nil, fmt.Errorf("catalog: header must be %s", strings.Join(csvHeader, ","))
+	}
+	cr.FieldsPerRecord = len(csvHeader)
+	var order []int
+	families := make(map[int]*Family)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalog: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		nums := make([]int, 0, 5)
+		for _, i := range []int{0, 3, 5, 6, 7} {
+			if i == 7 && rec[i] == "" {
+				nums = append(nums, 0)
+				continue
+			}
+			n, err := strconv.Atoi(rec[i])
+			if err != nil {
+				return nil, fmt.Errorf("catalog: line %d: %s: %q is not a number", line, csvHeader[i], rec[i])
+			}
+			nums = append(nums, n)
+		}
+		f, ok := families[nums[0]]
+		if !ok {
+			f = &Family{ID: nums[0], Name: rec[1], Kind: Kind(rec[2])}
+			families[f.ID] = f
+			order = append(order, f.ID)
+		} else if f.Name != rec[1] || f.Kind != Kind(rec[2]) {
+			return nil, fmt.Errorf("catalog: line %d: family %d is %s %q, not %s %q", line, f.ID, f.Kind, f.Name, rec[2], rec[1])
+		}
+		f.Models = append(f.Models, Model{Type: nums[1], Name: rec[4], Wheels: nums[2], Seats: nums[3], Doors: nums[4]})
+	}
+	list := make([]Family, len(order))
+	for i, id := range order {
+		list[i] = *families[id]
+	}
+	return NewCatalog(list...)
+}
+
+//go:embed catalog.json
+var defaultCatalog string
+
+// DefaultCatalog returns a new catalog with the car and motorbike families
+// of CarFactory and MotorbikeFactory, read from the embedded catalog.json.
+// BuildFactory serves CarFactoryType and MotorbikeFactoryType from it.
+func DefaultCatalog() *Catalog {
+	c, err := LoadCatalogJSON(strings.NewReader(defaultCatalog))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
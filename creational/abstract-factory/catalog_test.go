@@ -0,0 +1,166 @@
+package abstract_factory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalog(t *testing.T) {
+	c := DefaultCatalog()
+	for _, tc := range []struct {
+		family, model int
+		builtin       VehicleFactory
+	}{
+		{CarFactoryType, LuxuryCarType, new(CarFactory)},
+		{CarFactoryType, FamilyCarType, new(CarFactory)},
+		{MotorbikeFactoryType, SportMotorbikeType, new(MotorbikeFactory)},
+		{MotorbikeFactoryType, CruiseMotorbikeType, new(MotorbikeFactory)},
+	} {
+		f, err := c.Factory(tc.family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := f.GetVehicle(tc.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := tc.builtin.GetVehicle(tc.model)
+		if got.NumWheels() != want.NumWheels() || got.NumSeats() != want.NumSeats() {
+			t.Errorf("family %d model %d: got %+v, want %+v", tc.family, tc.model, got, want)
+		}
+		if car, ok := want.(Car); ok {
+			if c, ok := got.(Car); !ok || c.NumDoors() != car.NumDoors() {
+				t.Errorf("family %d model %d: doors differ", tc.family, tc.model)
+			}
+		}
+		if bike, ok := want.(Motorbike); ok {
+			if b, ok := got.(Motorbike); !ok || b.GetMotorbikeType() != bike.GetMotorbikeType() {
+				t.Errorf("family %d model %d: motorbike type differs", tc.family, tc.model)
+			}
+		}
+	}
+	if _, err := c.Factory(9); err == nil {
+		t.Error("unknown family accepted")
+	}
+	bf, _ := BuildFactory(CarFactoryType)
+	if v, err := bf.GetVehicle(LuxuryCarType); err != nil {
+		t.Fatal(err)
+	} else if _, ok := v.(*CatalogCar); !ok {
+		t.Errorf("BuildFactory(CarFactoryType) is not backed by the catalog: %T", v)
+	}
+	f, _ := c.FactoryByName("car")
+	if _, err := f.GetVehicle(9); err == nil {
+		t.Error("unknown model accepted")
+	}
+}
+
+const trucksCSV = `family_id,family,kind,type,model,wheels,seats,doors
+3,truck,car,1,pickup,4,3,2
+3,truck,car,2,lorry,6,2,2
+4,bus,vehicle,1,coach,6,50,
+`
+
+func TestLoadCatalogCSV(t *testing.T) {
+	c, err := LoadCatalogCSV(strings.NewReader(trucksCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs := c.Families(); len(fs) != 2 || fs[0].Name != "truck" || len(fs[0].Models) != 2 || fs[1].Name != "bus" {
+		t.Fatalf("families = %+v", fs)
+	}
+	f, err := c.FactoryByName("bus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus, err := f.GetVehicle(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bus.NumSeats() != 50 {
+		t.Errorf("bus seats = %d", bus.NumSeats())
+	}
+	if _, ok := bus.(Car); ok {
+		t.Error("a bus is not a car")
+	}
+}
+
+func TestLoadCatalogErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		load func(string) error
+		doc  string
+		want []string
+	}{
+		"json validation": {
+			load: loadJSON,
+			doc: `{"families": [
+				{"id": 0, "name": "car", "kind": "plane", "models": [{"type": 1, "name": "a", "wheels": 4, "seats": 2}, {"type": 1, "name": "", "wheels": 0, "seats": 2}]},
+				{"id": 5, "name": "bike", "kind": "motorbike", "models": [{"type": 1, "name": "b", "wheels": 2, "seats": 1, "doors": 1}]}
+			]}`,
+			want: []string{"id must be positive", `unknown kind "plane"`, "duplicate type", "name is required", "wheels must be positive", "only cars have doors"},
+		},
+		"json unknown field": {load: loadJSON, doc: `{"families": [{"id": 1, "wheelz": 3}]}`, want: []string{"wheelz"}},
+		"json duplicate id": {
+			load: loadJSON,
+			doc: `{"families": [
+				{"id": 3, "name": "a", "kind": "vehicle", "models": [{"type": 1, "name": "a", "wheels": 4, "seats": 2}]},
+				{"id": 3, "name": "b", "kind": "vehicle", "models": [{"type": 1, "name": "b", "wheels": 4, "seats": 2}]}
+			]}`,
+			want: []string{"id already used"},
+		},
+		"csv header":       {load: loadCSV, doc: "id,name\n", want: []string{"header must be"}},
+		"csv number":       {load: loadCSV, doc: csvHeaderLine + "3,truck,car,1,pickup,four,3,2\n", want: []string{"line 2", "wheels"}},
+		"csv inconsistent": {load: loadCSV, doc: csvHeaderLine + "3,truck,car,1,a,4,3,2\n3,bus,car,2,b,4,3,2\n", want: []string{"line 3", "family 3"}},
+		"csv car no doors": {load: loadCSV, doc: csvHeaderLine + "3,truck,car,1,pickup,4,3,\n", want: []string{"a car needs doors"}},
+		"csv field count":  {load: loadCSV, doc: csvHeaderLine + "3,truck\n", want: []string{"wrong number of fields"}},
+	} {
+		err := tc.load(tc.doc)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		for _, w := range tc.want {
+			if !strings.Contains(err.Error(), w) {
+				t.Errorf("%s: error %q doesn't mention %q", name, err, w)
+			}
+		}
+	}
+}
+
+const csvHeaderLine = "family_id,family,kind,type,model,wheels,seats,doors\n"
+
+func loadJSON(doc string) error {
+	_, err := LoadCatalogJSON(strings.NewReader(doc))
+	return err
+}
+
+func loadCSV(doc string) error {
+	_, err := LoadCatalogCSV(strings.NewReader(doc))
+	return err
+}
+
+func TestRegisterFamily(t *testing.T) {
+	c := DefaultCatalog()
+	truck := Family{ID: 3, Name: "truck", Kind: KindVehicle, Models: []Model{{Type: 1, Name: "tipper", Wheels: 6, Seats: 2}}}
+	if err := c.Register(truck); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(truck); err == nil {
+		t.Error("family registered twice")
+	}
+	f, _ := c.Factory(3)
+	// 3 is the invalid id of TestMotorbikeFactory.
+	if err := RegisterFactory(10, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterFactory(CarFactoryType, f); err == nil {
+		t.Error("built-in factory id taken")
+	}
+	bf, err := BuildFactory(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := bf.GetVehicle(1)
+	if err != nil || v.NumWheels() != 6 {
+		t.Errorf("GetVehicle() = %+v, %v", v, err)
+	}
+}
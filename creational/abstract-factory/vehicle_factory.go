@@ -2,6 +2,7 @@ package abstract_factory
 
 import (
 	"fmt"
+	"sync"
 )
 
 type VehicleFactory interface {
@@ -13,13 +14,44 @@ const (
 	MotorbikeFactoryType = 2
 )
 
+var (
+	factoriesMu         sync.RWMutex
+	registeredFactories = builtinFactories()
+)
+
+// builtinFactories returns the factories of the DefaultCatalog families, which
+// back the CarFactoryType and MotorbikeFactoryType ids.
+func builtinFactories() map[int]VehicleFactory {
+	c := DefaultCatalog()
+	factories := make(map[int]VehicleFactory)
+	for _, family := range c.Families() {
+		f, err := c.Factory(family.ID)
+		if err != nil {
+			panic(err)
+		}
+		factories[family.ID] = f
+	}
+	return factories
+}
+
+// RegisterFactory makes BuildFactory return f for the id, so new families
+// such as the ones of a Catalog can be added at runtime. The ids of the
+// built-in factories can't be taken.
+func RegisterFactory(id int, f VehicleFactory) error {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := registeredFactories[id]; ok {
+		return fmt.Errorf("factory with id %d already registered", id)
+	}
+	registeredFactories[id] = f
+	return nil
+}
+
 func BuildFactory(f int) (VehicleFactory, error) {
-	switch f {
-	case CarFactoryType:
-		return new(CarFactory), nil
-	case MotorbikeFactoryType:
-		return new(MotorbikeFactory), nil
-	default:
-		return nil, fmt.Errorf("factory with id %d not recognized", f)
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	if factory, ok := registeredFactories[f]; ok {
+		return factory, nil
 	}
+	return nil, fmt.Errorf("factory with id %d not recognized", f)
 }
@@ -22,3 +22,7 @@ func (b *BikeBuilder) SetStructure() BuildProcess {
 func (b *BikeBuilder) GetVehicle() VehicleProduct {
 	return b.v
 }
+
+func (b *BikeBuilder) Build() (VehicleProduct, error) {
+	return build(b.v)
+}
@@ -1,6 +1,10 @@
 package builder
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestBuilderPattern(t *testing.T) {
 
@@ -53,3 +57,87 @@ func TestBuilderPattern(t *testing.T) {
 		t.Errorf("Seats on a bus must be 30 and they were %d\n", bus.Seats)
 	}
 }
+
+func TestBuildValidates(t *testing.T) {
+	carBuilder := &CarBuilder{}
+	carBuilder.SetWheels()
+	_, err := carBuilder.Build()
+	var missing *MissingPartsError
+	if !errors.As(err, &missing) || strings.Join(missing.Parts, ",") != "seats,structure" {
+		t.Fatalf("half built car: %v", err)
+	}
+	car, err := carBuilder.SetSeats().SetStructure().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if car.Wheels != 4 || car.Seats != 5 || car.Structure != "Car" {
+		t.Errorf("unexpected car %+v", car)
+	}
+}
+
+func TestDirector(t *testing.T) {
+	var director ManufacturingDirector
+	if err := director.Construct(); !errors.Is(err, ErrNoBuilder) {
+		t.Errorf("Construct() without builder = %v", err)
+	}
+	if _, err := director.Build(); !errors.Is(err, ErrNoBuilder) {
+		t.Errorf("Build() without builder = %v", err)
+	}
+	director.SetBuilder(&BusBuilder{})
+	bus, err := director.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bus.Wheels != 8 || bus.Seats != 30 {
+		t.Errorf("unexpected bus %+v", bus)
+	}
+}
+
+func TestStepBuilder(t *testing.T) {
+	base := NewVehicle().Wheels(4).Seats(5).Structure("Car").Option("radio")
+	red, err := base.Color("red").Engine(Engine{Fuel: "electric", Power: 150}).Option("sunroof", "radio").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if red.Color != "red" || red.Engine.String() != "electric 150 kW" || strings.Join(red.Options, ",") != "radio,sunroof" {
+		t.Errorf("unexpected car %+v", red)
+	}
+	plain, err := base.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Color != "" || len(plain.Options) != 1 || plain.Engine.String() != "none" {
+		t.Errorf("steps leaked into the shared builder: %+v", plain)
+	}
+
+	_, err = NewVehicle().Wheels(0).Seats(2).Structure("").Engine(Engine{Fuel: "petrol"}).Build()
+	var missing *MissingPartsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("got %v, want missing parts", err)
+	}
+	if want := "vehicle is missing wheels, structure, engine fuel or power"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestGeneratedTruckBuilder(t *testing.T) {
+	_, err := NewTruckBuilder().WithPayload(10_000).Build()
+	if err == nil || err.Error() != "Truck is missing required Engine" {
+		t.Errorf("Build() without engine = %v", err)
+	}
+	opts := []string{"crane"}
+	base := NewTruckBuilder().WithPayload(10_000).WithEngine(Engine{Fuel: "diesel", Power: 300}).WithOptions(opts)
+	opts[0] = "changed"
+	truck, err := base.WithColor("red").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truck.Wheels != 6 || truck.Axles != 3 || truck.Color != "red" || truck.Options[0] != "crane" {
+		t.Errorf("unexpected truck %+v", truck)
+	}
+	truck.Options[0] = "tipper"
+	again, _ := base.Build()
+	if again.Color != "white" || again.Options[0] != "crane" {
+		t.Errorf("built truck shares state with the builder: %+v", again)
+	}
+}
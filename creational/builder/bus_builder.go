@@ -22,3 +22,7 @@ func (b *BusBuilder) SetStructure() BuildProcess {
 func (b *BusBuilder) GetVehicle() VehicleProduct {
 	return b.v
 }
+
+func (b *BusBuilder) Build() (VehicleProduct, error) {
+	return build(b.v)
+}
@@ -22,3 +22,7 @@ func (c *CarBuilder) SetStructure() BuildProcess {
 func (c *CarBuilder) GetVehicle() VehicleProduct {
 	return c.v
 }
+
+func (c *CarBuilder) Build() (VehicleProduct, error) {
+	return build(c.v)
+}
@@ -0,0 +1,154 @@
+package builder
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Clock drives a production line. The goroutines of the line are started
+// with Go, so a virtual clock knows when all of them are sleeping and time
+// can move forward. A virtual clock only starts them in Wait, once every one
+// of them was queued.
+type Clock interface {
+	// Now returns the time elapsed since the clock started.
+	Now() time.Duration
+	Sleep(d time.Duration)
+	// Go runs f in a new goroutine and Wait waits for every f to return.
+	Go(f func())
+	Wait()
+}
+
+// RealClock is a Clock following the wall clock.
+type RealClock struct {
+	start time.Time
+	wg    sync.WaitGroup
+}
+
+func NewRealClock() *RealClock {
+	return &RealClock{start: time.Now()}
+}
+
+func (c *RealClock) Now() time.Duration    { return time.Since(c.start) }
+func (c *RealClock) Sleep(d time.Duration) { time.Sleep(d) }
+func (c *RealClock) Wait()                 { c.wg.Wait() }
+
+func (c *RealClock) Go(f func()) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		f()
+	}()
+}
+
+// VirtualClock is a Clock for simulations: sleeping takes no real time. Only
+// one of its goroutines runs at a time, and when it sleeps or returns the one
+// with the earliest wake up time resumes, ties broken by the order in which
+// they went to sleep or were queued by Go. Nothing runs before Wait, so the
+// order in which the goroutines start doesn't depend on the scheduler and
+// runs are deterministic.
+type VirtualClock struct {
+	mu       sync.Mutex
+	now      time.Duration
+	running  bool
+	live     int
+	seq      int
+	sleepers sleepers
+	idle     *sync.Cond
+}
+
+func NewVirtualClock() *VirtualClock {
+	c := &VirtualClock{}
+	c.idle = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *VirtualClock) Now() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Go queues f to start at the current time. It starts once Wait was called
+// and every goroutine queued before it has yielded.
+func (c *VirtualClock) Go(f func()) {
+	c.mu.Lock()
+	c.live++
+	wake := c.enqueue(c.now)
+	c.mu.Unlock()
+	go func() {
+		<-wake
+		f()
+		c.mu.Lock()
+		c.live--
+		c.running = false
+		c.dispatch()
+		c.mu.Unlock()
+	}()
+}
+
+// Sleep must only be called by the goroutines started with Go.
+func (c *VirtualClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	wake := c.enqueue(c.now + max(d, 0))
+	c.running = false
+	c.dispatch()
+	c.mu.Unlock()
+	<-wake
+}
+
+// Wait starts the goroutines queued by Go and returns when every one of them
+// returned.
+func (c *VirtualClock) Wait() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dispatch()
+	for c.live > 0 {
+		c.idle.Wait()
+	}
+}
+
+func (c *VirtualClock) enqueue(at time.Duration) chan struct{} {
+	c.seq++
+	s := &sleeper{at: at, seq: c.seq, wake: make(chan struct{})}
+	heap.Push(&c.sleepers, s)
+	return s.wake
+}
+
+// dispatch resumes the next sleeper when nobody is running.
+func (c *VirtualClock) dispatch() {
+	if c.running {
+		return
+	}
+	if c.sleepers.Len() == 0 {
+		if c.live == 0 {
+			c.idle.Broadcast()
+		}
+		return
+	}
+	s := heap.Pop(&c.sleepers).(*sleeper)
+	c.now = s.at
+	c.running = true
+	close(s.wake)
+}
+
+type sleeper struct {
+	at   time.Duration
+	seq  int
+	wake chan struct{}
+}
+
+type sleepers []*sleeper
+
+func (s sleepers) Len() int { return len(s) }
+func (s sleepers) Less(i, j int) bool {
+	return s[i].at < s[j].at || s[i].at == s[j].at && s[i].seq < s[j].seq
+}
+func (s sleepers) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s *sleepers) Push(x any)   { *s = append(*s, x.(*sleeper)) }
+func (s *sleepers) Pop() any {
+	old := *s
+	x := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return x
+}
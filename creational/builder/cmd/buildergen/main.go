@@ -0,0 +1,370 @@
+// Command buildergen writes a fluent builder for a struct type, for use with
+// go generate:
+//
+//	//go:generate go run ./cmd/buildergen -type Truck
+//
+// reads the Go files of the current directory and writes truck_builder.go
+// with a TruckBuilder type: NewTruckBuilder, a With<Field> setter per field
+// and Build. Fields are configured with the builder struct tag:
+//
+//	Wheels int    `builder:"default=6"` // initial value, for basic types
+//	Load   int    `builder:"required"`  // Build fails until WithLoad is called
+//	cache  []byte `builder:"-"`         // no setter
+//
+// Builders are values: every setter returns a new builder and Build copies
+// slices and maps, so neither builders nor built values share state.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	typeName := flag.String("type", "", "struct type to write a builder for")
+	dir := flag.String("dir", ".", "package directory")
+	output := flag.String("output", "", "output file, defaults to <type>_builder.go in dir")
+	flag.Parse()
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	src, err := Generate(*dir, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(*dir, snake(*typeName)+"_builder.go")
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// field is a field of the struct as seen by the template.
+type field struct {
+	Name     string
+	Setter   string
+	Type     string
+	Default  string
+	Required bool
+	Clone    string // "slices" or "maps" when the value must be copied
+}
+
+type data struct {
+	Package string
+	Type    string
+	Imports []string
+	Fields  []field
+}
+
+// Generate returns the formatted source of the builder of typeName, declared
+// in the package in dir.
+func Generate(dir, typeName string) ([]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		spec := findType(file, typeName)
+		if spec == nil {
+			continue
+		}
+		d, err := describe(file, spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fset.Position(spec.Pos()), err)
+		}
+		return render(d)
+	}
+	return nil, fmt.Errorf("type %s not found in %s", typeName, dir)
+}
+
+func findType(file *ast.File, name string) *ast.TypeSpec {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gen.Specs {
+			if ts := s.(*ast.TypeSpec); ts.Name.Name == name {
+				return ts
+			}
+		}
+	}
+	return nil
+}
+
+func describe(file *ast.File, spec *ast.TypeSpec) (*data, error) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct", spec.Name.Name)
+	}
+	if spec.TypeParams != nil {
+		return nil, fmt.Errorf("generic type %s is not supported", spec.Name.Name)
+	}
+	d := &data{Package: file.Name.Name, Type: spec.Name.Name}
+	used := map[string]bool{"fmt": true, "strings": true}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("embedded field %s is not supported", types.ExprString(f.Type))
+		}
+		var tag string
+		if f.Tag != nil {
+			raw, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(raw).Get("builder")
+		}
+		if tag == "-" {
+			continue
+		}
+		collectPackages(f.Type, used)
+		for _, name := range f.Names {
+			fd := field{Name: name.Name, Setter: "With" + upperFirst(name.Name), Type: types.ExprString(f.Type)}
+			switch f.Type.(type) {
+			case *ast.ArrayType:
+				if f.Type.(*ast.ArrayType).Len == nil {
+					fd.Clone = "slices"
+				}
+			case *ast.MapType:
+				fd.Clone = "maps"
+			}
+			if fd.Clone != "" {
+				used[fd.Clone] = true
+			}
+			if err := parseTag(&fd, tag, f.Type); err != nil {
+				return nil, fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			d.Fields = append(d.Fields, fd)
+		}
+	}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if used[name] {
+			line := strconv.Quote(path)
+			if imp.Name != nil {
+				line = imp.Name.Name + " " + line
+			}
+			d.Imports = append(d.Imports, line)
+			delete(used, name)
+		}
+	}
+	for name := range used {
+		// the packages used by the generated code itself
+		if name == "fmt" || name == "strings" || name == "slices" || name == "maps" {
+			d.Imports = append(d.Imports, strconv.Quote(name))
+		}
+	}
+	slices.Sort(d.Imports)
+	return d, nil
+}
+
+// collectPackages records the package names used by a field type.
+func collectPackages(expr ast.Expr, used map[string]bool) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+func parseTag(fd *field, tag string, typ ast.Expr) error {
+	for _, opt := range strings.Split(tag, ",") {
+		switch key, value, _ := strings.Cut(opt, "="); key {
+		case "":
+		case "required":
+			fd.Required = true
+		case "default":
+			lit, err := literal(value, typ)
+			if err != nil {
+				return err
+			}
+			fd.Default = lit
+		default:
+			return fmt.Errorf("unknown builder tag option %q", key)
+		}
+	}
+	if fd.Required && fd.Default != "" {
+		return errors.New("a required field can't have a default")
+	}
+	return nil
+}
+
+// literal turns a tag default into a Go literal of a basic type. The value
+// is parsed with the size of the type and written back in canonical form,
+// so "t" becomes true and "0x10" becomes 16.
+func literal(value string, typ ast.Expr) (string, error) {
+	id, _ := typ.(*ast.Ident)
+	if id == nil {
+		return "", fmt.Errorf("default not supported for type %s", types.ExprString(typ))
+	}
+	invalid := fmt.Errorf("default %q is not a valid %s", value, id.Name)
+	switch id.Name {
+	case "string":
+		return strconv.Quote(value), nil
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatBool(b), nil
+	case "int", "int8", "int16", "int32", "int64":
+		n, err := strconv.ParseInt(value, 0, bitSize(id.Name))
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		n, err := strconv.ParseUint(value, 0, bitSize(id.Name))
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatUint(n, 10), nil
+	case "float32", "float64":
+		bits := bitSize(id.Name)
+		f, err := strconv.ParseFloat(value, bits)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", invalid
+		}
+		return strconv.FormatFloat(f, 'g', -1, bits), nil
+	}
+	return "", fmt.Errorf("default not supported for type %s", id.Name)
+}
+
+// bitSize returns the size of a basic numeric type.
+func bitSize(name string) int {
+	switch name {
+	case "int8", "uint8", "byte":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	case "int", "uint":
+		return strconv.IntSize
+	}
+	return 64
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// snake turns TruckTrailer into truck_trailer.
+func snake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var tmpl = template.Must(template.New("builder").Funcs(template.FuncMap{
+	"upper": upperFirst,
+}).Parse(`// Code generated by buildergen -type {{.Type}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.Type}}Builder builds values of type {{.Type}}. Setters return a new
+// builder and leave the receiver unchanged.
+type {{.Type}}Builder struct {
+	v {{.Type}}
+{{- range .Fields}}{{if .Required}}
+	has{{upper .Name}} bool
+{{- end}}{{end}}
+}
+
+// New{{.Type}}Builder returns a builder holding the default values.
+func New{{.Type}}Builder() {{.Type}}Builder {
+	return {{.Type}}Builder{v: {{.Type}}{
+{{- range .Fields}}{{if .Default}}
+		{{.Name}}: {{.Default}},
+{{- end}}{{end}}
+	}}
+}
+{{range .Fields}}
+// {{.Setter}} sets {{.Name}}.
+func (b {{$.Type}}Builder) {{.Setter}}(v {{.Type}}) {{$.Type}}Builder {
+	b.v.{{.Name}} = {{if .Clone}}{{.Clone}}.Clone(v){{else}}v{{end}}
+{{- if .Required}}
+	b.has{{upper .Name}} = true
+{{- end}}
+	return b
+}
+{{end}}
+// Build returns the {{.Type}}, or an error naming the required fields that
+// were not set.
+func (b {{.Type}}Builder) Build() ({{.Type}}, error) {
+	var missing []string
+{{- range .Fields}}{{if .Required}}
+	if !b.has{{upper .Name}} {
+		missing = append(missing, "{{.Name}}")
+	}
+{{- end}}{{end}}
+	if len(missing) > 0 {
+		return {{.Type}}{}, fmt.Errorf("{{.Type}} is missing required %s", strings.Join(missing, ", "))
+	}
+	v := b.v
+{{- range .Fields}}{{if .Clone}}
+	v.{{.Name}} = {{.Clone}}.Clone(v.{{.Name}})
+{{- end}}{{end}}
+	return v, nil
+}
+`))
+
+func render(d *data) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+	got, err := Generate("testdata", "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := "testdata/order_builder.golden"
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from %s, run go test -update\n%s", golden, got)
+	}
+}
+
+// TestTruckUpToDate fails when the builder package changed without running
+// go generate.
+func TestTruckUpToDate(t *testing.T) {
+	got, err := Generate("../..", "Truck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../truck_builder.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("truck_builder.go is stale, run go generate in creational/builder")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for typ, want := range map[string]string{
+		"Missing":            "type Missing not found",
+		"NotAStruct":         "is not a struct",
+		"Embedded":           "embedded field Order",
+		"BadDefault":         `default "many" is not a valid int`,
+		"OverflowDefault":    `default "1000" is not a valid int8`,
+		"InfDefault":         `default "inf" is not a valid float64`,
+		"UnsupportedDefault": "default not supported for type []int",
+		"RequiredDefault":    "can't have a default",
+		"UnknownOption":      `unknown builder tag option "optional"`,
+		"Generic":            "generic type",
+	} {
+		_, err := Generate("testdata", typ)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v doesn't mention %q", typ, err, want)
+		}
+	}
+}
+
+func TestSnake(t *testing.T) {
+	for in, want := range map[string]string{"Truck": "truck", "TruckTrailer": "truck_trailer", "order": "order"} {
+		if got := snake(in); got != want {
+			t.Errorf("snake(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package shop
+
+type NotAStruct int
+
+type Embedded struct {
+	Order
+}
+
+type BadDefault struct {
+	N int `builder:"default=many"`
+}
+
+type OverflowDefault struct {
+	Small int8 `builder:"default=1000"`
+}
+
+type InfDefault struct {
+	F float64 `builder:"default=inf"`
+}
+
+type UnsupportedDefault struct {
+	T []int `builder:"default=1"`
+}
+
+type RequiredDefault struct {
+	N int `builder:"required,default=2"`
+}
+
+type UnknownOption struct {
+	N int `builder:"optional"`
+}
+
+type Generic[T any] struct {
+	V T
+}
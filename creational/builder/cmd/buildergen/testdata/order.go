@@ -0,0 +1,24 @@
+package shop
+
+import (
+	"strings"
+	"time"
+
+	dec "math/big"
+)
+
+type Order struct {
+	ID       string `builder:"required"`
+	Quantity int    `builder:"default=1"`
+	Express  bool   `builder:"default=true"`
+	Weight   float64
+	Rush     bool    `builder:"default=t"`
+	Priority int8    `builder:"default=0x10"`
+	Ratio    float32 `builder:"default=.5"`
+	Timeout  time.Duration
+	Price    *dec.Rat `builder:"required"`
+	Tags     []string
+	Extra    map[string]string
+	note     string
+	cache    *strings.Builder `builder:"-"`
+}
@@ -4,5 +4,8 @@ type BuildProcess interface {
 	SetWheels() BuildProcess
 	SetSeats() BuildProcess
 	SetStructure() BuildProcess
+	// GetVehicle returns the product as it is, even half built.
 	GetVehicle() VehicleProduct
+	// Build returns the product once every required part was set.
+	Build() (VehicleProduct, error)
 }
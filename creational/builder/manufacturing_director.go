@@ -1,5 +1,10 @@
 package builder
 
+import "errors"
+
+// ErrNoBuilder is returned by the director when SetBuilder was never called.
+var ErrNoBuilder = errors.New("manufacturing director has no builder")
+
 type ManufacturingDirector struct {
 	builder BuildProcess
 }
@@ -8,6 +13,28 @@ func (f *ManufacturingDirector) SetBuilder(b BuildProcess) {
 	f.builder = b
 }
 
-func (f *ManufacturingDirector) Construct() {
-	f.builder.SetSeats().SetStructure().SetWheels()
+func (f *ManufacturingDirector) Construct() error {
+	return f.construct(nil)
+}
+
+// construct runs the steps of Construct, calling before ahead of each one.
+func (f *ManufacturingDirector) construct(before func(stage Stage)) error {
+	if f.builder == nil {
+		return ErrNoBuilder
+	}
+	for _, stage := range []Stage{SeatsStage, StructureStage, WheelsStage} {
+		if before != nil {
+			before(stage)
+		}
+		stage.apply(f.builder)
+	}
+	return nil
+}
+
+// Build constructs the vehicle and returns it validated.
+func (f *ManufacturingDirector) Build() (VehicleProduct, error) {
+	if err := f.Construct(); err != nil {
+		return VehicleProduct{}, err
+	}
+	return f.builder.Build()
 }
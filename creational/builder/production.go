@@ -0,0 +1,204 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stage is one of the Set* steps run by the ManufacturingDirector.
+type Stage int
+
+const (
+	SeatsStage Stage = iota
+	StructureStage
+	WheelsStage
+)
+
+func (s Stage) String() string {
+	switch s {
+	case SeatsStage:
+		return "seats"
+	case StructureStage:
+		return "structure"
+	case WheelsStage:
+		return "wheels"
+	}
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+func (s Stage) apply(b BuildProcess) {
+	switch s {
+	case SeatsStage:
+		b.SetSeats()
+	case StructureStage:
+		b.SetStructure()
+	case WheelsStage:
+		b.SetWheels()
+	}
+}
+
+// Model is a vehicle type made on the line: a new builder per vehicle and
+// how long each stage takes at a station.
+type Model struct {
+	New       func() BuildProcess
+	Durations map[Stage]time.Duration
+}
+
+// Order asks for one vehicle of a model, available to the stations from
+// Arrival on.
+type Order struct {
+	ID      int
+	Model   string
+	Arrival time.Duration
+}
+
+// ProductionLine is a factory floor with a bounded pool of assembly
+// stations. Each station runs a ManufacturingDirector on the next waiting
+// order, oldest first.
+type ProductionLine struct {
+	Stations int
+	Models   map[string]Model
+	// Clock defaults to a new VirtualClock, so a simulation takes no real
+	// time.
+	Clock Clock
+}
+
+// Built is an order that went through the line.
+type Built struct {
+	Order   Order
+	Vehicle VehicleProduct
+	Err     error // the error of Build, the vehicle is then the zero value
+	Station int
+	Start   time.Duration
+	End     time.Duration
+}
+
+// ProductionReport is the outcome of a run. Times are relative to the start
+// of the run.
+type ProductionReport struct {
+	Built    []Built // by completion time
+	Makespan time.Duration
+	// Throughput is the number of vehicles built per hour.
+	Throughput float64
+	// MaxQueue and AvgQueue are the highest and time weighted average number
+	// of orders waiting for a station.
+	MaxQueue int
+	AvgQueue float64
+	AvgWait  time.Duration
+	// Utilization is the share of the makespan each station was busy.
+	Utilization []float64
+}
+
+// Run builds every order and reports on the run. It fails before starting
+// when an order names an unknown model or there are no stations.
+func (l *ProductionLine) Run(orders []Order) (*ProductionReport, error) {
+	if l.Stations <= 0 {
+		return nil, fmt.Errorf("production line needs stations, has %d", l.Stations)
+	}
+	for _, o := range orders {
+		if _, ok := l.Models[o.Model]; !ok {
+			return nil, fmt.Errorf("order %d: unknown model %q", o.ID, o.Model)
+		}
+	}
+	clock := l.Clock
+	if clock == nil {
+		clock = NewVirtualClock()
+	}
+	pending := slices.Clone(orders)
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Arrival < pending[j].Arrival })
+	r := &run{line: l, clock: clock, pending: pending, busy: make([]time.Duration, l.Stations)}
+	for s := range l.Stations {
+		clock.Go(func() { r.station(s) })
+	}
+	clock.Wait()
+	return r.report(), nil
+}
+
+// run is the state shared by the stations of a Run.
+type run struct {
+	line    *ProductionLine
+	clock   Clock
+	mu      sync.Mutex
+	pending []Order // by arrival, not started yet
+	built   []Built
+	busy    []time.Duration
+}
+
+func (r *run) station(id int) {
+	for {
+		r.mu.Lock()
+		now := r.clock.Now()
+		if len(r.pending) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		next := r.pending[0]
+		if next.Arrival > now {
+			r.mu.Unlock()
+			r.clock.Sleep(next.Arrival - now)
+			continue
+		}
+		r.pending = r.pending[1:]
+		r.mu.Unlock()
+
+		b := r.build(next)
+		b.Station, b.Start, b.End = id, now, r.clock.Now()
+		r.mu.Lock()
+		r.busy[id] += b.End - b.Start
+		r.built = append(r.built, b)
+		r.mu.Unlock()
+	}
+}
+
+func (r *run) build(o Order) Built {
+	m := r.line.Models[o.Model]
+	b := m.New()
+	var director ManufacturingDirector
+	director.SetBuilder(b)
+	director.construct(func(s Stage) { r.clock.Sleep(m.Durations[s]) })
+	v, err := b.Build()
+	return Built{Order: o, Vehicle: v, Err: err}
+}
+
+func (r *run) report() *ProductionReport {
+	rep := &ProductionReport{Built: r.built, Utilization: make([]float64, len(r.busy))}
+	sort.SliceStable(rep.Built, func(i, j int) bool { return rep.Built[i].End < rep.Built[j].End })
+	// An order is queued from its arrival to its start. The queue length
+	// changes at those instants only, departures first on ties.
+	type change struct {
+		at    time.Duration
+		delta int
+	}
+	var changes []change
+	var wait time.Duration
+	for _, b := range rep.Built {
+		rep.Makespan = max(rep.Makespan, b.End)
+		wait += b.Start - b.Order.Arrival
+		if b.Start > b.Order.Arrival {
+			changes = append(changes, change{b.Order.Arrival, 1}, change{b.Start, -1})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].at < changes[j].at || changes[i].at == changes[j].at && changes[i].delta < changes[j].delta
+	})
+	queue := 0
+	for _, c := range changes {
+		queue += c.delta
+		rep.MaxQueue = max(rep.MaxQueue, queue)
+	}
+	if len(rep.Built) > 0 {
+		rep.AvgWait = wait / time.Duration(len(rep.Built))
+	}
+	if rep.Makespan > 0 {
+		rep.Throughput = float64(len(rep.Built)) / rep.Makespan.Hours()
+		// the area under the queue length is the total wait
+		rep.AvgQueue = float64(wait) / float64(rep.Makespan)
+		for i, busy := range r.busy {
+			rep.Utilization[i] = float64(busy) / float64(rep.Makespan)
+		}
+	}
+	return rep
+}
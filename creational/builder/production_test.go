@@ -0,0 +1,155 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// noStructure is a builder that forgets the structure.
+type noStructure struct{ *CarBuilder }
+
+func (b noStructure) SetStructure() BuildProcess { return b }
+
+func testLine(clock Clock) *ProductionLine {
+	durations := func(seats, structure, wheels time.Duration) map[Stage]time.Duration {
+		return map[Stage]time.Duration{SeatsStage: seats, StructureStage: structure, WheelsStage: wheels}
+	}
+	return &ProductionLine{
+		Stations: 2,
+		Clock:    clock,
+		Models: map[string]Model{
+			"car":    {New: func() BuildProcess { return &CarBuilder{} }, Durations: durations(10*time.Minute, 20*time.Minute, 10*time.Minute)},
+			"bike":   {New: func() BuildProcess { return &BikeBuilder{} }, Durations: durations(5*time.Minute, 10*time.Minute, 5*time.Minute)},
+			"bus":    {New: func() BuildProcess { return &BusBuilder{} }, Durations: durations(20*time.Minute, 40*time.Minute, 20*time.Minute)},
+			"broken": {New: func() BuildProcess { return noStructure{&CarBuilder{}} }},
+		},
+	}
+}
+
+var testOrders = []Order{
+	{ID: 5, Model: "bike", Arrival: 100 * time.Minute},
+	{ID: 1, Model: "car"},
+	{ID: 2, Model: "car"},
+	{ID: 3, Model: "bike"},
+	{ID: 4, Model: "bus", Arrival: 30 * time.Minute},
+}
+
+func TestProductionLine(t *testing.T) {
+	rep, err := testLine(nil).Run(testOrders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]struct {
+		station    int
+		start, end time.Duration
+	}{
+		1: {0, 0, 40 * time.Minute},
+		2: {1, 0, 40 * time.Minute},
+		3: {0, 40 * time.Minute, 60 * time.Minute},
+		4: {1, 40 * time.Minute, 120 * time.Minute},
+		5: {0, 100 * time.Minute, 120 * time.Minute},
+	}
+	if len(rep.Built) != len(want) {
+		t.Fatalf("built %d vehicles, want %d", len(rep.Built), len(want))
+	}
+	for _, b := range rep.Built {
+		w := want[b.Order.ID]
+		if b.Station != w.station || b.Start != w.start || b.End != w.end {
+			t.Errorf("order %d: station %d from %v to %v, want station %d from %v to %v",
+				b.Order.ID, b.Station, b.Start, b.End, w.station, w.start, w.end)
+		}
+		if b.Err != nil || b.Vehicle.Structure == "" {
+			t.Errorf("order %d: %+v, %v", b.Order.ID, b.Vehicle, b.Err)
+		}
+	}
+	if rep.Makespan != 2*time.Hour || rep.Throughput != 2.5 {
+		t.Errorf("makespan %v, throughput %v", rep.Makespan, rep.Throughput)
+	}
+	if rep.MaxQueue != 2 || rep.AvgWait != 10*time.Minute || rep.AvgQueue != 50.0/120 {
+		t.Errorf("max queue %d, avg queue %v, avg wait %v", rep.MaxQueue, rep.AvgQueue, rep.AvgWait)
+	}
+	if !reflect.DeepEqual(rep.Utilization, []float64{80.0 / 120, 1}) {
+		t.Errorf("utilization %v", rep.Utilization)
+	}
+
+	again, _ := testLine(NewVirtualClock()).Run(testOrders)
+	if !reflect.DeepEqual(rep, again) {
+		t.Error("virtual runs are not deterministic")
+	}
+}
+
+func TestProductionLineDeterministic(t *testing.T) {
+	orders := make([]Order, 400)
+	for i := range orders {
+		orders[i] = Order{ID: i, Model: []string{"car", "bike", "bus"}[i%3], Arrival: time.Duration(i%7) * time.Minute}
+	}
+	line := testLine(nil)
+	line.Stations = 200
+	first, err := line.Run(orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 50 {
+		rep, _ := line.Run(orders)
+		if !reflect.DeepEqual(first, rep) {
+			t.Fatalf("run %d differs from the first one", i+1)
+		}
+	}
+}
+
+func TestProductionLineErrors(t *testing.T) {
+	line := testLine(nil)
+	if _, err := line.Run([]Order{{ID: 1, Model: "plane"}}); err == nil {
+		t.Error("unknown model accepted")
+	}
+	line.Stations = 0
+	if _, err := line.Run(testOrders); err == nil {
+		t.Error("line without stations accepted")
+	}
+	rep, err := testLine(nil).Run([]Order{{ID: 1, Model: "broken"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Built) != 1 || rep.Built[0].Err == nil {
+		t.Errorf("broken vehicle built: %+v", rep.Built)
+	}
+}
+
+func TestProductionLineRealClock(t *testing.T) {
+	line := testLine(NewRealClock())
+	for name, m := range line.Models {
+		m.Durations = map[Stage]time.Duration{SeatsStage: time.Millisecond}
+		line.Models[name] = m
+	}
+	orders := make([]Order, 20)
+	for i := range orders {
+		orders[i] = Order{ID: i, Model: []string{"car", "bike", "bus"}[i%3]}
+	}
+	line.Stations = 4
+	rep, err := line.Run(orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Built) != len(orders) {
+		t.Errorf("built %d vehicles, want %d", len(rep.Built), len(orders))
+	}
+}
+
+func TestVirtualClock(t *testing.T) {
+	c := NewVirtualClock()
+	var log []string
+	for _, name := range []string{"a", "b"} {
+		c.Go(func() {
+			for i := range 3 {
+				c.Sleep(time.Duration(len(name)+i) * time.Second)
+				log = append(log, name+c.Now().String())
+			}
+		})
+	}
+	c.Wait()
+	want := []string{"a1s", "b1s", "a3s", "b3s", "a6s", "b6s"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("log = %v, want %v", log, want)
+	}
+}
@@ -0,0 +1,83 @@
+package builder
+
+import "slices"
+
+// The step builder makes the required parts impossible to skip: NewVehicle
+// returns a WheelsStep, which only offers Wheels, returning a SeatsStep and
+// so on. Build is only reachable once wheels, seats and structure are set,
+// after which engine, color and options can be added in any order.
+//
+//	v, err := NewVehicle().Wheels(4).Seats(5).Structure("Car").
+//		Engine(Engine{Fuel: "electric", Power: 150}).Color("red").Build()
+//
+// Every step returns a new builder, so a partial builder can be shared and
+// completed in different ways.
+
+type WheelsStep interface {
+	Wheels(n int) SeatsStep
+}
+
+type SeatsStep interface {
+	Seats(n int) StructureStep
+}
+
+type StructureStep interface {
+	Structure(s string) OptionalStep
+}
+
+type OptionalStep interface {
+	Engine(e Engine) OptionalStep
+	Color(c string) OptionalStep
+	Option(opts ...string) OptionalStep
+	Build() (VehicleProduct, error)
+}
+
+type stepBuilder struct {
+	v VehicleProduct
+}
+
+// NewVehicle starts a step builder.
+func NewVehicle() WheelsStep {
+	return stepBuilder{}
+}
+
+func (b stepBuilder) Wheels(n int) SeatsStep {
+	b.v.Wheels = n
+	return b
+}
+
+func (b stepBuilder) Seats(n int) StructureStep {
+	b.v.Seats = n
+	return b
+}
+
+func (b stepBuilder) Structure(s string) OptionalStep {
+	b.v.Structure = s
+	return b
+}
+
+func (b stepBuilder) Engine(e Engine) OptionalStep {
+	b.v.Engine = e
+	return b
+}
+
+func (b stepBuilder) Color(c string) OptionalStep {
+	b.v.Color = c
+	return b
+}
+
+// Option adds options, such as "sunroof", once each.
+func (b stepBuilder) Option(opts ...string) OptionalStep {
+	b.v.Options = slices.Clone(b.v.Options)
+	for _, o := range opts {
+		if !slices.Contains(b.v.Options, o) {
+			b.v.Options = append(b.v.Options, o)
+		}
+	}
+	return b
+}
+
+// Build validates the values given to the steps.
+func (b stepBuilder) Build() (VehicleProduct, error) {
+	return build(b.v)
+}
@@ -0,0 +1,15 @@
+package builder
+
+//go:generate go run ./cmd/buildergen -type Truck
+
+// Truck is built by the generated TruckBuilder instead of a hand written
+// BuildProcess.
+type Truck struct {
+	Wheels  int    `builder:"default=6"`
+	Seats   int    `builder:"default=2"`
+	Axles   int    `builder:"default=3"`
+	Payload int    `builder:"required"` // kg
+	Engine  Engine `builder:"required"`
+	Color   string `builder:"default=white"`
+	Options []string
+}
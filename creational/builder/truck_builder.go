@@ -0,0 +1,89 @@
+// Code generated by buildergen -type Truck; DO NOT EDIT.
+
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TruckBuilder builds values of type Truck. Setters return a new
+// builder and leave the receiver unchanged.
+type TruckBuilder struct {
+	v          Truck
+	hasPayload bool
+	hasEngine  bool
+}
+
+// NewTruckBuilder returns a builder holding the default values.
+func NewTruckBuilder() TruckBuilder {
+	return TruckBuilder{v: Truck{
+		Wheels: 6,
+		Seats:  2,
+		Axles:  3,
+		Color:  "white",
+	}}
+}
+
+// WithWheels sets Wheels.
+func (b TruckBuilder) WithWheels(v int) TruckBuilder {
+	b.v.Wheels = v
+	return b
+}
+
+// WithSeats sets Seats.
+func (b TruckBuilder) WithSeats(v int) TruckBuilder {
+	b.v.Seats = v
+	return b
+}
+
+// WithAxles sets Axles.
+func (b TruckBuilder) WithAxles(v int) TruckBuilder {
+	b.v.Axles = v
+	return b
+}
+
+// WithPayload sets Payload.
+func (b TruckBuilder) WithPayload(v int) TruckBuilder {
+	b.v.Payload = v
+	b.hasPayload = true
+	return b
+}
+
+// WithEngine sets Engine.
+func (b TruckBuilder) WithEngine(v Engine) TruckBuilder {
+	b.v.Engine = v
+	b.hasEngine = true
+	return b
+}
+
+// WithColor sets Color.
+func (b TruckBuilder) WithColor(v string) TruckBuilder {
+	b.v.Color = v
+	return b
+}
+
+// WithOptions sets Options.
+func (b TruckBuilder) WithOptions(v []string) TruckBuilder {
+	b.v.Options = slices.Clone(v)
+	return b
+}
+
+// Build returns the Truck, or an error naming the required fields that
+// were not set.
+func (b TruckBuilder) Build() (Truck, error) {
+	var missing []string
+	if !b.hasPayload {
+		missing = append(missing, "Payload")
+	}
+	if !b.hasEngine {
+		missing = append(missing, "Engine")
+	}
+	if len(missing) > 0 {
+		return Truck{}, fmt.Errorf("Truck is missing required %s", strings.Join(missing, ", "))
+	}
+	v := b.v
+	v.Options = slices.Clone(v.Options)
+	return v, nil
+}
@@ -1,7 +1,72 @@
 package builder
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type VehicleProduct struct {
 	Wheels    int
 	Seats     int
 	Structure string
+	Engine    Engine
+	Color     string
+	Options   []string
+}
+
+// Engine is the optional engine of a product. The zero value means no
+// engine, as for a bicycle.
+type Engine struct {
+	Fuel  string
+	Power int // kW
+}
+
+func (e Engine) String() string {
+	if e == (Engine{}) {
+		return "none"
+	}
+	return fmt.Sprintf("%s %d kW", e.Fuel, e.Power)
+}
+
+// MissingPartsError is returned by Build when required parts were not set
+// or have invalid values.
+type MissingPartsError struct {
+	Parts []string
+}
+
+func (e *MissingPartsError) Error() string {
+	return "vehicle is missing " + strings.Join(e.Parts, ", ")
+}
+
+// Validate reports the missing parts of the product: wheels, seats and the
+// structure are required, an engine needs both a fuel and a positive power.
+func (v VehicleProduct) Validate() error {
+	var missing []string
+	if v.Wheels <= 0 {
+		missing = append(missing, "wheels")
+	}
+	if v.Seats <= 0 {
+		missing = append(missing, "seats")
+	}
+	if v.Structure == "" {
+		missing = append(missing, "structure")
+	}
+	if v.Engine != (Engine{}) && (v.Engine.Fuel == "" || v.Engine.Power <= 0) {
+		missing = append(missing, "engine fuel or power")
+	}
+	if len(missing) > 0 {
+		return &MissingPartsError{Parts: missing}
+	}
+	return nil
+}
+
+// build validates a copy of the product, so later changes to the builder
+// don't leak into products already returned.
+func build(v VehicleProduct) (VehicleProduct, error) {
+	v.Options = slices.Clone(v.Options)
+	if err := v.Validate(); err != nil {
+		return VehicleProduct{}, err
+	}
+	return v, nil
 }
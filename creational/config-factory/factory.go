@@ -0,0 +1,218 @@
+// Package config_factory builds objects from declarative specs such as
+//
+//	{"type": "luxury_car", "options": {"color": "red", "engine": {"type": "electric", "options": {"kw": 300}}}}
+//
+// instead of integer constants. Every registered type declares the schema of
+// its options, which are validated before the type is built, and options can
+// hold nested specs built by another factory.
+package config_factory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Spec describes an object to build: its registered type name and options.
+type Spec struct {
+	Type    string         `json:"type"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// SpecFromMap converts a generic decoded document, as produced by
+// encoding/json or any YAML decoder into map[string]any, to a Spec.
+func SpecFromMap(m map[string]any) (Spec, error) {
+	t, ok := m["type"].(string)
+	if !ok {
+		return Spec{}, errors.New(`spec without a "type" string`)
+	}
+	s := Spec{Type: t}
+	switch o := m["options"].(type) {
+	case nil:
+	case map[string]any:
+		s.Options = o
+	default:
+		return Spec{}, fmt.Errorf(`"options" of %q must be an object`, t)
+	}
+	for k := range m {
+		if k != "type" && k != "options" {
+			return Spec{}, fmt.Errorf("unknown spec key %q in %q", k, t)
+		}
+	}
+	return s, nil
+}
+
+// SpecBuilder is the non generic side of a Factory, used to build nested
+// specs.
+type SpecBuilder interface {
+	BuildAny(Spec) (any, error)
+	Types() []string
+}
+
+// Builder creates a value from validated options.
+type Builder[T any] func(Options) (T, error)
+
+type registration[T any] struct {
+	schema Schema
+	build  Builder[T]
+}
+
+// Factory builds values of type T from specs. It is safe for concurrent use.
+type Factory[T any] struct {
+	name  string
+	mu    sync.RWMutex
+	types map[string]registration[T]
+}
+
+// New returns an empty factory. name is used in error messages, for example
+// "vehicle" or "engine".
+func New[T any](name string) *Factory[T] {
+	return &Factory[T]{name: name, types: make(map[string]registration[T])}
+}
+
+// Register adds a type to the factory. It fails when the name is taken or
+// the schema is inconsistent.
+func (f *Factory[T]) Register(name string, schema Schema, build Builder[T]) error {
+	if err := schema.check(); err != nil {
+		return fmt.Errorf("%s %q: %w", f.name, name, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.types[name]; ok {
+		return fmt.Errorf("%s type %q already registered", f.name, name)
+	}
+	f.types[name] = registration[T]{schema: schema, build: build}
+	return nil
+}
+
+// Types returns the registered type names, sorted.
+func (f *Factory[T]) Types() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return slices.Sorted(maps.Keys(f.types))
+}
+
+// Build validates the spec options against the schema of its type, builds
+// the nested specs and then the value itself. Validation errors are reported
+// together in a *ValidationError.
+func (f *Factory[T]) Build(s Spec) (T, error) {
+	var zero T
+	f.mu.RLock()
+	reg, ok := f.types[s.Type]
+	f.mu.RUnlock()
+	if !ok {
+		return zero, &UnknownTypeError{Kind: f.name, Type: s.Type, Suggestions: suggest(s.Type, f.Types())}
+	}
+	opts, errs := reg.schema.validate(s.Options)
+	if len(errs) > 0 {
+		return zero, &ValidationError{Type: s.Type, Problems: errs}
+	}
+	v, err := reg.build(opts)
+	if err != nil {
+		return zero, fmt.Errorf("building %s %q: %w", f.name, s.Type, err)
+	}
+	return v, nil
+}
+
+// BuildAny is Build returning any, so factories of different types can be
+// nested.
+func (f *Factory[T]) BuildAny(s Spec) (any, error) {
+	return f.Build(s)
+}
+
+// BuildJSON decodes a JSON spec and builds it.
+func (f *Factory[T]) BuildJSON(data []byte) (T, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		var zero T
+		return zero, err
+	}
+	s, err := SpecFromMap(m)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return f.Build(s)
+}
+
+// UnknownTypeError is returned when a spec names a type that wasn't
+// registered.
+type UnknownTypeError struct {
+	Kind        string
+	Type        string
+	Suggestions []string
+}
+
+func (e *UnknownTypeError) Error() string {
+	msg := fmt.Sprintf("unknown %s type %q", e.Kind, e.Type)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", quoteJoin(e.Suggestions))
+	}
+	return msg
+}
+
+// ValidationError lists every problem found in the options of a spec,
+// including the ones of nested specs, with their path.
+type ValidationError struct {
+	Type     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid options for %q: %s", e.Type, strings.Join(e.Problems, "; "))
+}
+
+// suggest returns the candidates close to name, closest first.
+func suggest(name string, candidates []string) []string {
+	type scored struct {
+		name string
+		dist int
+	}
+	var near []scored
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if d <= max(2, len(c)/3) || (name != "" && strings.Contains(c, name)) {
+			near = append(near, scored{c, d})
+		}
+	}
+	sort.SliceStable(near, func(i, j int) bool { return near[i].dist < near[j].dist })
+	out := make([]string, len(near))
+	for i, s := range near {
+		out[i] = s.name
+	}
+	return out
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func quoteJoin(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(q, " or ")
+}
@@ -0,0 +1,183 @@
+package config_factory
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	abstract_factory "github.com/antoniofmoliveira/patterns/creational/abstract-factory"
+)
+
+func newFactory() *Factory[abstract_factory.Vehicle] {
+	return NewVehicleFactory(NewEngineFactory())
+}
+
+func TestBuildJSON(t *testing.T) {
+	v, err := newFactory().BuildJSON([]byte(`{
+		"type": "luxury_car",
+		"options": {"color": "red", "engine": {"type": "electric", "options": {"kw": 300}}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	car, ok := v.(abstract_factory.Car)
+	if !ok {
+		t.Fatalf("%T is not a Car", v)
+	}
+	if car.NumDoors() != 4 || v.NumWheels() != 4 || v.NumSeats() != 5 {
+		t.Errorf("unexpected car %+v", v)
+	}
+	c := v.(*Car)
+	if c.Color != "red" {
+		t.Errorf("color = %q, want red", c.Color)
+	}
+	if got := c.Engine.Describe(); got != "electric 300 kW, 60 kWh battery" {
+		t.Errorf("engine = %q", got)
+	}
+}
+
+func TestBuildMotorbike(t *testing.T) {
+	v, err := newFactory().Build(Spec{
+		Type:    "cruise_motorbike",
+		Options: map[string]any{"engine": Spec{Type: "combustion", Options: map[string]any{"kw": 70, "fuel": "diesel"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := v.(abstract_factory.Motorbike)
+	if !ok || m.GetMotorbikeType() != abstract_factory.CruiseMotorbikeType || v.NumSeats() != 2 {
+		t.Errorf("unexpected motorbike %+v", v)
+	}
+	if got := v.(*Motorbike).Engine.Describe(); got != "diesel 70 kW" {
+		t.Errorf("engine = %q", got)
+	}
+}
+
+func TestUnknownType(t *testing.T) {
+	_, err := newFactory().Build(Spec{Type: "luxery_car"})
+	var unknown *UnknownTypeError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("got %v, want an UnknownTypeError", err)
+	}
+	if len(unknown.Suggestions) == 0 || unknown.Suggestions[0] != "luxury_car" {
+		t.Errorf("suggestions = %v", unknown.Suggestions)
+	}
+	if !strings.Contains(err.Error(), `did you mean "luxury_car"`) {
+		t.Errorf("error = %q", err)
+	}
+	_, err = newFactory().Build(Spec{Type: "spaceship"})
+	if !errors.As(err, &unknown) || len(unknown.Suggestions) != 0 {
+		t.Errorf("got %v, want no suggestion", err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	_, err := newFactory().BuildJSON([]byte(`{
+		"type": "family_car",
+		"options": {"colour": "red", "seats": 4.5, "engine": {"type": "combustion", "options": {"fuel": "coal"}}}
+	}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want a ValidationError", err)
+	}
+	want := []string{
+		"seats: 4.5 is not an integer",
+		"engine.kw: required",
+		`engine.fuel: "coal" is not one of "petrol" or "diesel"`,
+		`colour: unknown option, did you mean "color"?`,
+	}
+	if len(verr.Problems) != len(want) {
+		t.Fatalf("problems = %q, want %q", verr.Problems, want)
+	}
+	for i := range want {
+		if verr.Problems[i] != want[i] {
+			t.Errorf("problem %d = %q, want %q", i, verr.Problems[i], want[i])
+		}
+	}
+}
+
+func TestNestedUnknownType(t *testing.T) {
+	_, err := newFactory().BuildJSON([]byte(`{"type": "sport_motorbike", "options": {"engine": {"type": "electrik"}}}`))
+	if err == nil || !strings.Contains(err.Error(), `engine: unknown engine type "electrik", did you mean "electric"?`) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestBuilderError(t *testing.T) {
+	_, err := newFactory().BuildJSON([]byte(`{"type": "sport_motorbike", "options": {"engine": {"type": "electric", "options": {"kw": -1}}}}`))
+	if err == nil || !strings.Contains(err.Error(), "power must be positive") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestBadSpecs(t *testing.T) {
+	for _, doc := range []string{
+		`{"options": {}}`,
+		`{"type": "luxury_car", "options": 3}`,
+		`{"type": "luxury_car", "extra": true}`,
+		`[1, 2]`,
+	} {
+		if _, err := newFactory().BuildJSON([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", doc)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	f := New[int]("number")
+	build := func(o Options) (int, error) { return o.Int("n"), nil }
+	if err := f.Register("n", Schema{{Name: "n", Kind: Int, Default: 7}}, build); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Register("n", nil, build); err == nil {
+		t.Error("duplicate type registered")
+	}
+	for _, s := range []Schema{
+		{{Kind: Int}},
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "o", Kind: Object}},
+		{{Name: "r", Required: true, Default: 1}},
+		{{Name: "i", Kind: Int, Default: "5"}},
+		{{Name: "i", Kind: Int, Default: 1.5}},
+		{{Name: "s", Kind: String, Default: "x", OneOf: []string{"y"}}},
+	} {
+		if err := f.Register("bad", s, build); err == nil {
+			t.Errorf("schema %+v accepted", s)
+		}
+	}
+	if n, err := f.Build(Spec{Type: "n"}); err != nil || n != 7 {
+		t.Errorf("Build() = %d, %v, want the default 7", n, err)
+	}
+	if got := f.Types(); len(got) != 1 || got[0] != "n" {
+		t.Errorf("Types() = %v", got)
+	}
+
+	// defaults are converted to the kind of their field
+	g := New[float64]("mixed")
+	schema := Schema{{Name: "x", Kind: Float, Default: 1}, {Name: "n", Kind: Int, Default: int64(5)}}
+	if err := g.Register("m", schema, func(o Options) (float64, error) {
+		return o.Float("x") + float64(o.Int("n")), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := g.Build(Spec{Type: "m"}); err != nil || v != 6 {
+		t.Errorf("Build() = %v, %v, want 6 from the defaults", v, err)
+	}
+}
+
+func TestIntRange(t *testing.T) {
+	f := Field{Name: "n", Kind: Int}
+	if strconv.IntSize == 64 {
+		one := 1
+		if v, errs := f.convert(float64(1 << 40)); len(errs) > 0 || v != one<<40 {
+			t.Errorf("convert(2^40) = %v, %v", v, errs)
+		}
+	}
+	for _, bad := range []any{1e19, -1e19, json.Number("9223372036854775808"), 2.5} {
+		if _, errs := f.convert(bad); len(errs) == 0 {
+			t.Errorf("convert(%v) accepted", bad)
+		}
+	}
+}
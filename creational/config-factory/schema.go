@@ -0,0 +1,260 @@
+package config_factory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of an option value.
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Float
+	Bool
+	// Object is a nested spec built by the Factory of its field.
+	Object
+)
+
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Object:
+		return "object"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Field describes one option of a type. Default is used when an optional
+// field is missing, OneOf restricts String fields to a set of values, and
+// Factory builds the nested spec of Object fields.
+type Field struct {
+	Name     string
+	Kind     Kind
+	Required bool
+	Default  any
+	OneOf    []string
+	Factory  SpecBuilder
+}
+
+// Schema is the list of options accepted by a type. Options that aren't in
+// the schema are rejected.
+type Schema []Field
+
+// check reports the schema mistakes made at registration time.
+func (s Schema) check() error {
+	seen := make(map[string]bool, len(s))
+	for _, f := range s {
+		switch {
+		case f.Name == "":
+			return errors.New("field without a name")
+		case seen[f.Name]:
+			return fmt.Errorf("field %q declared twice", f.Name)
+		case f.Kind == Object && f.Factory == nil:
+			return fmt.Errorf("object field %q without a factory", f.Name)
+		case f.Required && f.Default != nil:
+			return fmt.Errorf("required field %q with a default", f.Name)
+		}
+		if f.Default != nil {
+			if _, errs := f.convert(f.Default); len(errs) > 0 {
+				return fmt.Errorf("default of field %q: %s", f.Name, strings.Join(errs, "; "))
+			}
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+func (s Schema) names() []string {
+	names := make([]string, len(s))
+	for i, f := range s {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// validate checks the raw options against the schema and returns them
+// converted to their kind, with nested specs already built.
+func (s Schema) validate(raw map[string]any) (Options, []string) {
+	var problems []string
+	values := make(map[string]any, len(s))
+	for _, f := range s {
+		v, ok := raw[f.Name]
+		if !ok || v == nil {
+			switch {
+			case f.Required:
+				problems = append(problems, fmt.Sprintf("%s: required", f.Name))
+			case f.Default != nil:
+				// check made sure the default converts
+				values[f.Name], _ = f.convert(f.Default)
+			}
+			continue
+		}
+		cv, errs := f.convert(v)
+		problems = append(problems, errs...)
+		if len(errs) == 0 {
+			values[f.Name] = cv
+		}
+	}
+	var unknown []string
+	for name := range raw {
+		if !slices.Contains(s.names(), name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		msg := fmt.Sprintf("%s: unknown option", name)
+		if sug := suggest(name, s.names()); len(sug) > 0 {
+			msg += fmt.Sprintf(", did you mean %s?", quoteJoin(sug))
+		}
+		problems = append(problems, msg)
+	}
+	return Options{values: values}, problems
+}
+
+// convert turns a decoded value into the Go type of the field kind.
+func (f Field) convert(v any) (any, []string) {
+	mismatch := []string{fmt.Sprintf("%s: expected %s, got %T", f.Name, f.Kind, v)}
+	switch f.Kind {
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, mismatch
+		}
+		if len(f.OneOf) > 0 && !slices.Contains(f.OneOf, s) {
+			return nil, []string{fmt.Sprintf("%s: %q is not one of %s", f.Name, s, quoteJoin(f.OneOf))}
+		}
+		return s, nil
+	case Int:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			if int64(int(n)) == n {
+				return int(n), nil
+			}
+		case float64:
+			// -MinInt is 2^63 or 2^31, exactly representable unlike MaxInt
+			if n == math.Trunc(n) && n >= math.MinInt && n < -float64(math.MinInt) {
+				return int(n), nil
+			}
+		case json.Number:
+			if i, err := strconv.ParseInt(string(n), 10, strconv.IntSize); err == nil {
+				return int(i), nil
+			}
+		default:
+			return nil, mismatch
+		}
+		return nil, []string{fmt.Sprintf("%s: %v is not an integer", f.Name, v)}
+	case Float:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case json.Number:
+			x, err := n.Float64()
+			if err != nil {
+				return nil, mismatch
+			}
+			return x, nil
+		}
+		return nil, mismatch
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, mismatch
+		}
+		return b, nil
+	case Object:
+		var spec Spec
+		switch o := v.(type) {
+		case Spec:
+			spec = o
+		case map[string]any:
+			var err error
+			if spec, err = SpecFromMap(o); err != nil {
+				return nil, []string{fmt.Sprintf("%s: %v", f.Name, err)}
+			}
+		default:
+			return nil, mismatch
+		}
+		built, err := f.Factory.BuildAny(spec)
+		var verr *ValidationError
+		switch {
+		case errors.As(err, &verr):
+			nested := make([]string, len(verr.Problems))
+			for i, p := range verr.Problems {
+				nested[i] = f.Name + "." + p
+			}
+			return nil, nested
+		case err != nil:
+			return nil, []string{fmt.Sprintf("%s: %v", f.Name, err)}
+		}
+		return built, nil
+	}
+	return nil, mismatch
+}
+
+// Options are the validated options passed to a Builder. Missing optional
+// fields without a default read as the zero value of their kind.
+type Options struct {
+	values map[string]any
+}
+
+// Has tells whether the option was given or has a default.
+func (o Options) Has(name string) bool {
+	_, ok := o.values[name]
+	return ok
+}
+
+func (o Options) String(name string) string {
+	s, _ := o.values[name].(string)
+	return s
+}
+
+func (o Options) Int(name string) int {
+	n, _ := o.values[name].(int)
+	return n
+}
+
+func (o Options) Float(name string) float64 {
+	x, _ := o.values[name].(float64)
+	return x
+}
+
+func (o Options) Bool(name string) bool {
+	b, _ := o.values[name].(bool)
+	return b
+}
+
+// Object returns the value built from a nested spec, nil when missing.
+func (o Options) Object(name string) any {
+	return o.values[name]
+}
+
+// Get returns the option converted to V, and false when it is missing or of
+// another type. It is handy for Object fields:
+//
+//	engine, _ := Get[Engine](opts, "engine")
+func Get[V any](o Options, name string) (V, bool) {
+	v, ok := o.values[name].(V)
+	return v, ok
+}
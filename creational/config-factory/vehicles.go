@@ -0,0 +1,130 @@
+package config_factory
+
+import (
+	"fmt"
+
+	abstract_factory "github.com/antoniofmoliveira/patterns/creational/abstract-factory"
+)
+
+// Engine is the nested part of the vehicle specs.
+type Engine interface {
+	Power() int
+	Describe() string
+}
+
+// Electric is an electric engine with a battery.
+type Electric struct {
+	KW         int
+	BatteryKWh int
+}
+
+func (e *Electric) Power() int { return e.KW }
+
+func (e *Electric) Describe() string {
+	return fmt.Sprintf("electric %d kW, %d kWh battery", e.KW, e.BatteryKWh)
+}
+
+// Combustion is a petrol or diesel engine.
+type Combustion struct {
+	KW   int
+	Fuel string
+}
+
+func (c *Combustion) Power() int { return c.KW }
+
+func (c *Combustion) Describe() string {
+	return fmt.Sprintf("%s %d kW", c.Fuel, c.KW)
+}
+
+// Car is a configured car. It implements abstract_factory.Vehicle and
+// abstract_factory.Car.
+type Car struct {
+	Model  string
+	Color  string
+	Doors  int
+	Seats  int
+	Engine Engine
+}
+
+func (c *Car) NumWheels() int { return 4 }
+func (c *Car) NumSeats() int  { return c.Seats }
+func (c *Car) NumDoors() int  { return c.Doors }
+
+// Motorbike is a configured motorbike. It implements abstract_factory.Vehicle
+// and abstract_factory.Motorbike.
+type Motorbike struct {
+	Model  string
+	Type   int
+	Seats  int
+	Engine Engine
+}
+
+func (m *Motorbike) NumWheels() int        { return 2 }
+func (m *Motorbike) NumSeats() int         { return m.Seats }
+func (m *Motorbike) GetMotorbikeType() int { return m.Type }
+
+// NewEngineFactory returns a factory of the "electric" and "combustion"
+// engines.
+func NewEngineFactory() *Factory[Engine] {
+	f := New[Engine]("engine")
+	must(f.Register("electric", Schema{
+		{Name: "kw", Kind: Int, Required: true},
+		{Name: "battery_kwh", Kind: Int, Default: 60},
+	}, func(o Options) (Engine, error) {
+		if o.Int("kw") <= 0 {
+			return nil, fmt.Errorf("power must be positive, got %d kW", o.Int("kw"))
+		}
+		return &Electric{KW: o.Int("kw"), BatteryKWh: o.Int("battery_kwh")}, nil
+	}))
+	must(f.Register("combustion", Schema{
+		{Name: "kw", Kind: Int, Required: true},
+		{Name: "fuel", Kind: String, Default: "petrol", OneOf: []string{"petrol", "diesel"}},
+	}, func(o Options) (Engine, error) {
+		if o.Int("kw") <= 0 {
+			return nil, fmt.Errorf("power must be positive, got %d kW", o.Int("kw"))
+		}
+		return &Combustion{KW: o.Int("kw"), Fuel: o.String("fuel")}, nil
+	}))
+	return f
+}
+
+// NewVehicleFactory returns a factory of the vehicles of the abstract
+// factory, configurable through specs. Every vehicle requires an engine
+// spec, built by engines.
+func NewVehicleFactory(engines SpecBuilder) *Factory[abstract_factory.Vehicle] {
+	f := New[abstract_factory.Vehicle]("vehicle")
+	engine := Field{Name: "engine", Kind: Object, Required: true, Factory: engines}
+	car := func(model string, doors int) Builder[abstract_factory.Vehicle] {
+		return func(o Options) (abstract_factory.Vehicle, error) {
+			e, _ := Get[Engine](o, "engine")
+			return &Car{Model: model, Color: o.String("color"), Doors: doors, Seats: o.Int("seats"), Engine: e}, nil
+		}
+	}
+	must(f.Register("luxury_car", Schema{
+		{Name: "color", Kind: String, Default: "black"},
+		{Name: "seats", Kind: Int, Default: 5},
+		engine,
+	}, car("luxury", 4)))
+	must(f.Register("family_car", Schema{
+		{Name: "color", Kind: String, Default: "white"},
+		{Name: "seats", Kind: Int, Default: 5},
+		engine,
+	}, car("family", 5)))
+	motorbike := func(model string, kind, seats int) Builder[abstract_factory.Vehicle] {
+		return func(o Options) (abstract_factory.Vehicle, error) {
+			e, _ := Get[Engine](o, "engine")
+			return &Motorbike{Model: model, Type: kind, Seats: seats, Engine: e}, nil
+		}
+	}
+	must(f.Register("sport_motorbike", Schema{engine},
+		motorbike("sport", abstract_factory.SportMotorbikeType, 1)))
+	must(f.Register("cruise_motorbike", Schema{engine},
+		motorbike("cruise", abstract_factory.CruiseMotorbikeType, 2)))
+	return f
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,365 @@
+// Package di is a small dependency injection container. Constructors are
+// registered by the type they return and their parameters are resolved from
+// the container, so the examples no longer need to wire their dependencies by
+// hand with new(...).
+package di
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Lifetime tells how long a constructed value is reused.
+type Lifetime int
+
+const (
+	// Singleton values are built once per container.
+	Singleton Lifetime = iota
+	// Transient values are built every time they are resolved.
+	Transient
+	// Scoped values are built once per Scope and can't be resolved from the
+	// container itself.
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	}
+	return fmt.Sprintf("Lifetime(%d)", int(l))
+}
+
+var (
+	// ErrClosed is returned when resolving from a closed container or scope.
+	ErrClosed = errors.New("di: closed")
+	errorType = reflect.TypeFor[error]()
+)
+
+type provider struct {
+	lifetime Lifetime
+	ctor     reflect.Value
+	params   []reflect.Type
+	hasErr   bool
+}
+
+// Container holds the providers and the singletons. It is safe for
+// concurrent use, but constructors must not resolve from the container
+// themselves: they get their dependencies as parameters.
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	instances map[reflect.Type]reflect.Value
+	closers   []io.Closer
+	closed    bool
+}
+
+// New returns an empty container.
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+		instances: make(map[reflect.Type]reflect.Value),
+	}
+}
+
+// Provide registers a constructor. ctor must be a function returning either
+// a value or a value and an error; the type of the value is the one it is
+// registered for and its parameters are resolved when it is called.
+func (c *Container) Provide(lifetime Lifetime, ctor any) error {
+	v := reflect.ValueOf(ctor)
+	if !v.IsValid() || v.Kind() == reflect.Func && v.IsNil() {
+		return errors.New("di: constructor must be a function, got nil")
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		return fmt.Errorf("di: constructor must be a function, got %s", t)
+	}
+	if t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) || t.Out(0) == errorType {
+		return fmt.Errorf("di: constructor %s must return a value and optionally an error", t)
+	}
+	if t.IsVariadic() {
+		return fmt.Errorf("di: constructor %s can't be variadic", t)
+	}
+	if lifetime < Singleton || lifetime > Scoped {
+		return fmt.Errorf("di: unknown %s", lifetime)
+	}
+	p := &provider{lifetime: lifetime, ctor: v, hasErr: t.NumOut() == 2}
+	for i := range t.NumIn() {
+		p.params = append(p.params, t.In(i))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.providers[t.Out(0)]; ok {
+		return fmt.Errorf("di: provider for %s already registered", t.Out(0))
+	}
+	c.providers[t.Out(0)] = p
+	return nil
+}
+
+// Value registers an already built singleton. When it has a Close method it
+// is closed with the container, as if it had been built at registration.
+func Value[T any](c *Container, v T) error {
+	t := reflect.TypeFor[T]()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.providers[t]; ok {
+		return fmt.Errorf("di: provider for %s already registered", t)
+	}
+	c.providers[t] = &provider{lifetime: Singleton}
+	c.instances[t] = reflect.ValueOf(&v).Elem()
+	if cl, ok := closerOf(c.instances[t]); ok {
+		c.closers = append(c.closers, cl)
+	}
+	return nil
+}
+
+// Scope is a unit of work, such as a request, holding its own scoped values.
+// Close it when the work is done.
+type Scope struct {
+	c         *Container
+	instances map[reflect.Type]reflect.Value
+	closers   []io.Closer
+	closed    bool
+}
+
+// NewScope returns a new scope of the container.
+func (c *Container) NewScope() *Scope {
+	return &Scope{c: c, instances: make(map[reflect.Type]reflect.Value)}
+}
+
+// Resolver is either a Container or a Scope.
+type Resolver interface {
+	resolve(t reflect.Type) (reflect.Value, error)
+}
+
+// Resolve returns the value registered for T, building it and its
+// dependencies as needed.
+func Resolve[T any](r Resolver) (T, error) {
+	v, err := r.resolve(reflect.TypeFor[T]())
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	// a constructor of an interface type may return nil, which is the zero T
+	x, _ := v.Interface().(T)
+	return x, nil
+}
+
+// MustResolve is Resolve panicking on error, for the wiring done at startup.
+func MustResolve[T any](r Resolver) T {
+	v, err := Resolve[T](r)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (c *Container) resolve(t reflect.Type) (reflect.Value, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return reflect.Value{}, ErrClosed
+	}
+	return (&resolution{c: c}).get(t)
+}
+
+func (s *Scope) resolve(t reflect.Type) (reflect.Value, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	if s.closed || s.c.closed {
+		return reflect.Value{}, ErrClosed
+	}
+	return (&resolution{c: s.c, s: s}).get(t)
+}
+
+// resolution is a single Resolve call. path is the chain of types being
+// built, used to detect cycles and to explain errors.
+type resolution struct {
+	c    *Container
+	s    *Scope
+	path []reflect.Type
+	// singleton is set while building a singleton, which must not capture
+	// scoped values.
+	singleton bool
+}
+
+func (r *resolution) get(t reflect.Type) (reflect.Value, error) {
+	if i := slices.Index(r.path, t); i >= 0 {
+		return reflect.Value{}, &CycleError{Path: append(slices.Clone(r.path[i:]), t)}
+	}
+	p, ok := r.c.providers[t]
+	if !ok {
+		return reflect.Value{}, &MissingProviderError{Type: t, Path: slices.Clone(r.path)}
+	}
+	switch p.lifetime {
+	case Singleton:
+		if v, ok := r.c.instances[t]; ok {
+			return v, nil
+		}
+	case Scoped:
+		if r.s == nil {
+			return reflect.Value{}, fmt.Errorf("di: scoped %s resolved outside a scope%s", t, neededBy(r.path))
+		}
+		if r.singleton {
+			return reflect.Value{}, fmt.Errorf("di: singleton%s can't depend on scoped %s", neededBy(r.path), t)
+		}
+		if v, ok := r.s.instances[t]; ok {
+			return v, nil
+		}
+	}
+	wasSingleton := r.singleton
+	r.singleton = wasSingleton || p.lifetime == Singleton
+	r.path = append(r.path, t)
+	defer func() {
+		r.path = r.path[:len(r.path)-1]
+		r.singleton = wasSingleton
+	}()
+	args := make([]reflect.Value, len(p.params))
+	for i, pt := range p.params {
+		v, err := r.get(pt)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = v
+	}
+	out := p.ctor.Call(args)
+	if p.hasErr && !out[1].IsNil() {
+		return reflect.Value{}, &ConstructorError{Path: slices.Clone(r.path), Err: out[1].Interface().(error)}
+	}
+	v := out[0]
+	closer, hasCloser := closerOf(v)
+	switch {
+	case p.lifetime == Singleton:
+		r.c.instances[t] = v
+	case p.lifetime == Scoped:
+		r.s.instances[t] = v
+	}
+	// Transients belong to the scope resolving them, unless a singleton
+	// keeps them alive.
+	if hasCloser {
+		if r.s != nil && (p.lifetime == Scoped || !r.singleton) {
+			r.s.closers = append(r.s.closers, closer)
+		} else {
+			r.c.closers = append(r.c.closers, closer)
+		}
+	}
+	return v, nil
+}
+
+// closerOf returns the close hook of values implementing io.Closer or
+// having a Close method without result.
+func closerOf(v reflect.Value) (io.Closer, bool) {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, false
+	}
+	switch cl := v.Interface().(type) {
+	case io.Closer:
+		return cl, true
+	case interface{ Close() }:
+		return closeFunc(func() error { cl.Close(); return nil }), true
+	}
+	return nil, false
+}
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error { return f() }
+
+// Close closes the values built in the scope, scoped and transient, in the
+// reverse order of construction.
+func (s *Scope) Close() error {
+	s.c.mu.Lock()
+	if s.closed {
+		s.c.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	closers := s.closers
+	s.closers = nil
+	s.c.mu.Unlock()
+	return closeAll(closers)
+}
+
+// Close closes the singletons, and the transients resolved from the
+// container, in the reverse order of construction: a value is closed before
+// its dependencies. Errors are joined.
+func (c *Container) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	closers := c.closers
+	c.closers = nil
+	c.mu.Unlock()
+	return closeAll(closers)
+}
+
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for _, cl := range slices.Backward(closers) {
+		errs = append(errs, cl.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// MissingProviderError is returned when no constructor returns Type. Path
+// is the chain of types that needed it.
+type MissingProviderError struct {
+	Type reflect.Type
+	Path []reflect.Type
+}
+
+func (e *MissingProviderError) Error() string {
+	return fmt.Sprintf("di: no provider for %s%s", e.Type, neededBy(e.Path))
+}
+
+// CycleError is returned when a type depends on itself. Path starts and
+// ends with that type.
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	return "di: dependency cycle " + pathString(e.Path)
+}
+
+// ConstructorError wraps the error returned by the constructor of the last
+// type of Path.
+type ConstructorError struct {
+	Path []reflect.Type
+	Err  error
+}
+
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf("di: building %s: %v", pathString(e.Path), e.Err)
+}
+
+func (e *ConstructorError) Unwrap() error {
+	return e.Err
+}
+
+func neededBy(path []reflect.Type) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return " (needed by " + pathString(path) + ")"
+}
+
+func pathString(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
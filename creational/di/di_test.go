@@ -0,0 +1,201 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Config struct{ DSN string }
+
+type DB struct {
+	cfg *Config
+	log *[]string
+}
+
+func (db *DB) Close() error {
+	*db.log = append(*db.log, "db")
+	return nil
+}
+
+type Repo struct{ db *DB }
+
+type Request struct {
+	ID  int
+	log *[]string
+}
+
+func (r *Request) Close() { *r.log = append(*r.log, "request") }
+
+type Handler struct {
+	repo *Repo
+	req  *Request
+}
+
+func newContainer(t *testing.T, log *[]string) *Container {
+	t.Helper()
+	c := New()
+	ids := 0
+	for _, p := range []struct {
+		lt   Lifetime
+		ctor any
+	}{
+		{Singleton, func() *Config { return &Config{DSN: "mem"} }},
+		{Singleton, func(cfg *Config) (*DB, error) { return &DB{cfg: cfg, log: log}, nil }},
+		{Transient, func(db *DB) *Repo { return &Repo{db: db} }},
+		{Scoped, func() *Request { ids++; return &Request{ID: ids, log: log} }},
+		{Transient, func(r *Repo, req *Request) *Handler { return &Handler{repo: r, req: req} }},
+	} {
+		if err := c.Provide(p.lt, p.ctor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestLifetimes(t *testing.T) {
+	var log []string
+	c := newContainer(t, &log)
+	r1 := MustResolve[*Repo](c)
+	r2 := MustResolve[*Repo](c)
+	if r1 == r2 {
+		t.Error("transient resolved twice is the same value")
+	}
+	if r1.db != r2.db || r1.db.cfg.DSN != "mem" {
+		t.Error("singleton built twice")
+	}
+
+	s1, s2 := c.NewScope(), c.NewScope()
+	h1, h2 := MustResolve[*Handler](s1), MustResolve[*Handler](s1)
+	h3 := MustResolve[*Handler](s2)
+	if h1 == h2 || h1.req != h2.req {
+		t.Error("scoped value not shared within the scope")
+	}
+	if h1.req == h3.req {
+		t.Error("scoped value shared between scopes")
+	}
+	if _, err := Resolve[*Request](c); err == nil || !strings.Contains(err.Error(), "outside a scope") {
+		t.Errorf("scoped resolved from the container: %v", err)
+	}
+
+	if err := s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve[*Handler](s1); !errors.Is(err, ErrClosed) {
+		t.Errorf("resolve from closed scope: %v", err)
+	}
+	s2.Close()
+	c.Close()
+	if got := strings.Join(log, ","); got != "request,request,db" {
+		t.Errorf("close order = %s", got)
+	}
+}
+
+type A struct{}
+type B struct{}
+type C struct{}
+
+func TestCycle(t *testing.T) {
+	c := New()
+	c.Provide(Transient, func(*B) *A { return nil })
+	c.Provide(Transient, func(*C) *B { return nil })
+	c.Provide(Transient, func(*A) *C { return nil })
+	_, err := Resolve[*A](c)
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("got %v, want a cycle", err)
+	}
+	if want := "di: dependency cycle *di.A -> *di.B -> *di.C -> *di.A"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestResolveNilInterface(t *testing.T) {
+	c := New()
+	if err := c.Provide(Singleton, func() Logger { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := Resolve[Logger](c); err != nil || l != nil {
+		t.Errorf("Resolve() = %v, %v, want a nil Logger", l, err)
+	}
+}
+
+func TestMissingProvider(t *testing.T) {
+	c := New()
+	c.Provide(Transient, func(*Config) *DB { return nil })
+	c.Provide(Transient, func(*DB) *Repo { return nil })
+	_, err := Resolve[*Repo](c)
+	var missing *MissingProviderError
+	if !errors.As(err, &missing) {
+		t.Fatalf("got %v, want a missing provider", err)
+	}
+	if want := "di: no provider for *di.Config (needed by *di.Repo -> *di.DB)"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestConstructorError(t *testing.T) {
+	boom := errors.New("boom")
+	c := New()
+	c.Provide(Singleton, func() (*DB, error) { return nil, boom })
+	c.Provide(Transient, func(*DB) *Repo { return nil })
+	_, err := Resolve[*Repo](c)
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "*di.Repo -> *di.DB") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestCaptiveScoped(t *testing.T) {
+	c := New()
+	c.Provide(Scoped, func() *Request { return &Request{} })
+	c.Provide(Singleton, func(*Request) *Handler { return &Handler{} })
+	if _, err := Resolve[*Handler](c.NewScope()); err == nil || !strings.Contains(err.Error(), "can't depend on scoped") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestProvideErrors(t *testing.T) {
+	c := New()
+	for _, ctor := range []any{
+		nil,
+		(func() *A)(nil),
+		42,
+		func() {},
+		func() error { return nil },
+		func() (*A, *B) { return nil, nil },
+		func(...int) *A { return nil },
+	} {
+		if err := c.Provide(Transient, ctor); err == nil {
+			t.Errorf("%T accepted", ctor)
+		}
+	}
+	c.Provide(Transient, func() *A { return nil })
+	if err := c.Provide(Singleton, func() *A { return nil }); err == nil {
+		t.Error("duplicate provider accepted")
+	}
+}
+
+type Logger interface{ Log(string) }
+
+type sliceLogger struct{ lines *[]string }
+
+func (l sliceLogger) Log(s string) { *l.lines = append(*l.lines, s) }
+
+func TestValueAndInterfaces(t *testing.T) {
+	var lines, log []string
+	c := New()
+	if err := Value[Logger](c, sliceLogger{&lines}); err != nil {
+		t.Fatal(err)
+	}
+	Value(c, &DB{log: &log})
+	c.Provide(Transient, func(l Logger, db *DB) *Repo { l.Log("repo"); return &Repo{db: db} })
+	MustResolve[*Repo](c)
+	MustResolve[Logger](c).Log("direct")
+	if strings.Join(lines, ",") != "repo,direct" {
+		t.Errorf("lines = %v", lines)
+	}
+	c.Close()
+	if len(log) != 1 {
+		t.Errorf("value not closed: %v", log)
+	}
+}
@@ -2,65 +2,129 @@ package factory
 
 import (
 	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/antoniofmoliveira/patterns/money"
 )
 
-// The `PaymentMethod` interface defines a contract for payment methods. Here's what each method does:
-// - `Pay(amount float32) string`: This method takes an `amount` of type `float32` as input and returns a `string` representing the payment method used.
-// In other words, any type that implements the `PaymentMethod` interface must provide an implementation for the `Pay` method.
+// PaymentMethod is anything a customer can pay with. Pay charges the amount
+// and returns a receipt, or a *PaymentError telling which step failed and why.
 type PaymentMethod interface {
-	Pay(amount float32) string
+	Pay(amount money.Money) (Receipt, error)
 }
 
-// PaymentMethods is an enumeration of possible payment methods.
-type PaymentMethods int
+// CardPaymentMethod is a payment method that goes through a gateway in two
+// steps: the amount is first authorized (held) and then captured. Pay does
+// both at once. Captured payments can be refunded, fully or partially.
+type CardPaymentMethod interface {
+	PaymentMethod
+	Authorize(amount money.Money) (Authorization, error)
+	Capture(a Authorization) (Receipt, error)
+	Void(a Authorization) error
+	Refund(r Receipt, amount money.Money) (Receipt, error)
+}
 
-// The enumeration values represent the different payment methods.
-const (
-	Cash PaymentMethods = iota + 1
-	DebitCard
+// Options are given to the constructors of payment methods.
+type Options struct {
+	// Gateway processes card payments.
+	Gateway Gateway
+	// Account is the card or account charged by card payments.
+	Account string
+}
+
+// Constructor creates a payment method.
+type Constructor func(Options) (PaymentMethod, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
 )
 
-// GetPaymentMethod returns a PaymentMethod instance for the given PaymentMethods
-// value, or an error if the value is not recognized.
-func GetPaymentMethod(m PaymentMethods) (PaymentMethod, error) {
-	switch m {
-	case Cash:
-		return new(CashPM), nil
-	case DebitCard:
-		// return new(DebitCardPM), nil
-		return new(CreditCardPM), nil
-	default:
-		return nil, fmt.Errorf("payment method %d not recognized", m)
+// Register makes a payment method available under name. It fails if the name
+// is already taken or c is nil.
+func Register(name string, c Constructor) error {
+	if c == nil {
+		return fmt.Errorf("payment method %q: constructor must not be nil", name)
 	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("payment method %q already registered", name)
+	}
+	registry[name] = c
+	return nil
 }
 
-// CashPM represents the cash payment method.
-type CashPM struct{}
+// New creates the payment method registered under name.
+func New(name string, opts Options) (PaymentMethod, error) {
+	registryMu.RLock()
+	c, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("payment method %q not recognized", name)
+	}
+	return c(opts)
+}
 
-// Pay takes an amount of type float32 as input and returns a string representing the
-// payment method used. In this case, it returns a string indicating that the payment
-// was made using cash, with the amount formatted to two decimal places.
-func (c *CashPM) Pay(amount float32) string {
-	return fmt.Sprintf("%0.2f paid using cash\n", amount)
+// Names returns the registered payment method names, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
 }
 
-// DebitCardPM represents the debit card payment method.
-type DebitCardPM struct{}
+// PaymentMethods is an enumeration of the built-in payment methods.
+type PaymentMethods int
+
+// The enumeration values represent the different payment methods.
+const (
+	Cash PaymentMethods = iota + 1
+	DebitCard
+	CreditCard
+)
 
-// Pay takes an amount of type float32 as input and returns a string representing the
-// payment method used. In this case, it returns a string indicating that the payment
-// was made using debit card, with the amount formatted to two decimal places.
-func (c *DebitCardPM) Pay(amount float32) string {
-	return fmt.Sprintf("%#0.2f paid using debit card\n", amount)
+var paymentMethodNames = map[PaymentMethods]string{
+	Cash:       "cash",
+	DebitCard:  "debit_card",
+	CreditCard: "credit_card",
 }
 
-// CreditCardPM represents the credit card payment method.
-type CreditCardPM struct{}
+// DefaultGateway is the gateway used by GetPaymentMethod. Every account of it
+// starts with the same balance in euros, which is enough for the examples.
+var DefaultGateway = &FakeGateway{DefaultBalance: money.MustNew(1_000_000_00, "EUR")}
 
-// Pay takes an amount of type float32 as input and returns a string representing the
-// payment method used. In this case, it returns a string indicating that the payment
-// was made using credit card, with the amount formatted to two decimal places.
-func (d *CreditCardPM) Pay(amount float32) string {
-	return fmt.Sprintf("%#0.2f paid using new credit card implementation\n", amount)
-	// return fmt.Sprintf("%#0.2f paid using debit card (new)\n", amount)
+// GetPaymentMethod returns the built-in payment method for m, charging the
+// "default" account of DefaultGateway for cards, or an error if m is not
+// recognized.
+func GetPaymentMethod(m PaymentMethods) (PaymentMethod, error) {
+	name, ok := paymentMethodNames[m]
+	if !ok {
+		return nil, fmt.Errorf("payment method %d not recognized", m)
+	}
+	return New(name, Options{Gateway: DefaultGateway, Account: "default"})
+}
+
+func init() {
+	builtins := map[string]Constructor{
+		"cash": func(Options) (PaymentMethod, error) {
+			return new(CashPM), nil
+		},
+		"debit_card": func(o Options) (PaymentMethod, error) {
+			return NewDebitCard(o)
+		},
+		"credit_card": func(o Options) (PaymentMethod, error) {
+			return NewCreditCard(o)
+		},
+	}
+	for name, c := range builtins {
+		if err := Register(name, c); err != nil {
+			panic(err)
+		}
+	}
 }
@@ -3,6 +3,8 @@ package factory
 import (
 	"strings"
 	"testing"
+
+	"github.com/antoniofmoliveira/patterns/money"
 )
 
 // This is a Go test function that verifies the behavior of the `GetPaymentMethod` function when retrieving the "Cash" payment method. It checks that:
@@ -16,7 +18,11 @@ func TestCreatePaymentMethodCash(t *testing.T) {
 	if err != nil {
 		t.Fatal("A payment method of type 'Cash' must exist")
 	}
-	msg := payment.Pay(10.30)
+	receipt, err := payment.Pay(money.MustParse("10.30", "EUR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := receipt.String()
 	if !strings.Contains(msg, "paid using cash") {
 		t.Error("The cash payment method message wasn't correct")
 	}
@@ -30,9 +36,13 @@ func TestCreatePaymentMethodCash(t *testing.T) {
 func TestGetPaymentMethodDebitCard(t *testing.T) {
 	payment, err := GetPaymentMethod(DebitCard)
 	if err != nil {
-		t.Error("A payment method of type 'DebitCard' must exist")
+		t.Fatal("A payment method of type 'DebitCard' must exist")
+	}
+	receipt, err := payment.Pay(money.MustParse("22.30", "EUR"))
+	if err != nil {
+		t.Fatal(err)
 	}
-	msg := payment.Pay(22.30)
+	msg := receipt.String()
 	if !strings.Contains(msg, "paid using debit card") {
 		t.Error("The debit card payment method message wasn't correct")
 	}
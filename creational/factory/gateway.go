@@ -0,0 +1,201 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+// Errors returned by gateways.
+var (
+	ErrDeclined             = errors.New("declined")
+	ErrInsufficientFunds    = errors.New("insufficient funds")
+	ErrUnknownAuthorization = errors.New("unknown authorization")
+	ErrUnknownTransaction   = errors.New("unknown transaction")
+	ErrRefundExceeded       = errors.New("refund exceeds the captured amount")
+)
+
+// Gateway is the payment processor behind card payments.
+type Gateway interface {
+	Authorize(account string, amount money.Money) (authID string, err error)
+	Capture(authID string) (txID string, err error)
+	Void(authID string) error
+	Refund(txID string, amount money.Money) error
+}
+
+type fakeAuth struct {
+	account string
+	amount  money.Money
+}
+
+type fakeTx struct {
+	account  string
+	amount   money.Money
+	refunded money.Money
+}
+
+// FakeGateway is an in-memory Gateway for tests and examples. Accounts not
+// opened with Open start with DefaultBalance, and every account only accepts
+// amounts in the currency of its balance. It is safe for concurrent use.
+type FakeGateway struct {
+	DefaultBalance money.Money
+
+	mu       sync.Mutex
+	balances map[string]money.Money
+	held     map[string]money.Money
+	declined map[string]string
+	failing  map[string]error
+	auths    map[string]fakeAuth
+	txs      map[string]fakeTx
+	seq      int
+}
+
+// NewFakeGateway returns a gateway where unknown accounts have no money.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{}
+}
+
+func (g *FakeGateway) lazyInit() {
+	if g.balances == nil {
+		g.balances = make(map[string]money.Money)
+		g.held = make(map[string]money.Money)
+		g.declined = make(map[string]string)
+		g.failing = make(map[string]error)
+		g.auths = make(map[string]fakeAuth)
+		g.txs = make(map[string]fakeTx)
+	}
+}
+
+func (g *FakeGateway) balance(account string) money.Money {
+	b, ok := g.balances[account]
+	if !ok {
+		b = g.DefaultBalance
+		g.balances[account] = b
+	}
+	return b
+}
+
+func (g *FakeGateway) nextID(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("%s-%06d", prefix, g.seq)
+}
+
+// Open sets the balance of an account, which also sets its currency.
+func (g *FakeGateway) Open(account string, balance money.Money) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	g.balances[account] = balance
+}
+
+// Decline makes every authorization of the account fail with ErrDeclined.
+func (g *FakeGateway) Decline(account, reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	g.declined[account] = reason
+}
+
+// FailCaptures makes every capture on the account fail with err, leaving the
+// authorization open.
+func (g *FakeGateway) FailCaptures(account string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	g.failing[account] = err
+}
+
+// Balance returns the balance of an account, not counting the held amounts.
+func (g *FakeGateway) Balance(account string) money.Money {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	return g.balance(account)
+}
+
+// Available returns the balance of an account minus the held amounts.
+func (g *FakeGateway) Available(account string) money.Money {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	return g.available(account)
+}
+
+func (g *FakeGateway) available(account string) money.Money {
+	return g.balance(account).MustAdd(g.held[account].Neg())
+}
+
+func (g *FakeGateway) Authorize(account string, amount money.Money) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	if reason, ok := g.declined[account]; ok {
+		return "", fmt.Errorf("%w: %s", ErrDeclined, reason)
+	}
+	c, err := g.available(account).Cmp(amount)
+	if err != nil {
+		return "", err
+	}
+	if c < 0 {
+		return "", ErrInsufficientFunds
+	}
+	id := g.nextID("auth")
+	g.held[account] = g.held[account].MustAdd(amount)
+	g.auths[id] = fakeAuth{account: account, amount: amount}
+	return id, nil
+}
+
+func (g *FakeGateway) Capture(authID string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	a, ok := g.auths[authID]
+	if !ok {
+		return "", ErrUnknownAuthorization
+	}
+	if err := g.failing[a.account]; err != nil {
+		return "", err
+	}
+	delete(g.auths, authID)
+	g.held[a.account] = g.held[a.account].MustAdd(a.amount.Neg())
+	g.balances[a.account] = g.balances[a.account].MustAdd(a.amount.Neg())
+	id := g.nextID("tx")
+	g.txs[id] = fakeTx{account: a.account, amount: a.amount}
+	return id, nil
+}
+
+func (g *FakeGateway) Void(authID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	a, ok := g.auths[authID]
+	if !ok {
+		return ErrUnknownAuthorization
+	}
+	delete(g.auths, authID)
+	g.held[a.account] = g.held[a.account].MustAdd(a.amount.Neg())
+	return nil
+}
+
+func (g *FakeGateway) Refund(txID string, amount money.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lazyInit()
+	tx, ok := g.txs[txID]
+	if !ok {
+		return ErrUnknownTransaction
+	}
+	refunded, err := tx.refunded.Add(amount)
+	if err != nil {
+		return err
+	}
+	if c, _ := refunded.Cmp(tx.amount); c > 0 {
+		return ErrRefundExceeded
+	}
+	tx.refunded = refunded
+	g.txs[txID] = tx
+	g.balances[tx.account] = g.balances[tx.account].MustAdd(amount)
+	return nil
+}
@@ -0,0 +1,175 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+// Receipt is the proof of a payment.
+type Receipt struct {
+	// Method describes how it was paid, such as "cash" or "debit card".
+	Method        string
+	Amount        money.Money
+	Authorization string
+	Transaction   string
+	// Refunded is the total amount given back so far.
+	Refunded money.Money
+}
+
+func (r Receipt) String() string {
+	return fmt.Sprintf("%s paid using %s\n", r.Amount, r.Method)
+}
+
+// Authorization is an amount held on an account, waiting to be captured or
+// voided.
+type Authorization struct {
+	ID     string
+	Amount money.Money
+}
+
+// PaymentError is returned when a payment step fails. Err is one of the
+// gateway errors, such as ErrInsufficientFunds or ErrDeclined, so callers can
+// use errors.Is.
+type PaymentError struct {
+	Method string
+	Step   string
+	Err    error
+}
+
+func (e *PaymentError) Error() string {
+	return fmt.Sprintf("%s %s failed: %v", e.Method, e.Step, e.Err)
+}
+
+func (e *PaymentError) Unwrap() error {
+	return e.Err
+}
+
+// ErrInvalidAmount is returned for zero or negative amounts.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// CashPM represents the cash payment method.
+type CashPM struct{}
+
+// Pay returns a receipt for the amount paid in cash.
+func (c *CashPM) Pay(amount money.Money) (Receipt, error) {
+	if amount.Sign() <= 0 {
+		return Receipt{}, &PaymentError{Method: "cash", Step: "pay", Err: ErrInvalidAmount}
+	}
+	return Receipt{Method: "cash", Amount: amount}, nil
+}
+
+// cardPM is the two step flow shared by debit and credit cards.
+type cardPM struct {
+	method  string
+	gateway Gateway
+	account string
+}
+
+func newCard(method string, o Options) (cardPM, error) {
+	if o.Gateway == nil {
+		return cardPM{}, fmt.Errorf("%s needs a gateway", method)
+	}
+	if o.Account == "" {
+		return cardPM{}, fmt.Errorf("%s needs an account", method)
+	}
+	return cardPM{method: method, gateway: o.Gateway, account: o.Account}, nil
+}
+
+func (c *cardPM) fail(step string, err error) error {
+	return &PaymentError{Method: c.method, Step: step, Err: err}
+}
+
+// Authorize holds the amount on the card account.
+func (c *cardPM) Authorize(amount money.Money) (Authorization, error) {
+	if amount.Sign() <= 0 {
+		return Authorization{}, c.fail("authorization", ErrInvalidAmount)
+	}
+	id, err := c.gateway.Authorize(c.account, amount)
+	if err != nil {
+		return Authorization{}, c.fail("authorization", err)
+	}
+	return Authorization{ID: id, Amount: amount}, nil
+}
+
+// Capture charges an authorized amount.
+func (c *cardPM) Capture(a Authorization) (Receipt, error) {
+	tx, err := c.gateway.Capture(a.ID)
+	if err != nil {
+		return Receipt{}, c.fail("capture", err)
+	}
+	return Receipt{Method: c.method, Amount: a.Amount, Authorization: a.ID, Transaction: tx}, nil
+}
+
+// Void releases an authorized amount without charging it.
+func (c *cardPM) Void(a Authorization) error {
+	if err := c.gateway.Void(a.ID); err != nil {
+		return c.fail("void", err)
+	}
+	return nil
+}
+
+// Pay authorizes and captures the amount. When the capture fails the
+// authorization is voided, so no amount stays held on the account.
+func (c *cardPM) Pay(amount money.Money) (Receipt, error) {
+	a, err := c.Authorize(amount)
+	if err != nil {
+		return Receipt{}, err
+	}
+	r, err := c.Capture(a)
+	if err != nil {
+		if verr := c.Void(a); verr != nil {
+			return Receipt{}, errors.Join(err, verr)
+		}
+		return Receipt{}, err
+	}
+	return r, nil
+}
+
+// Refund gives back part or all of a captured payment and returns the
+// updated receipt.
+func (c *cardPM) Refund(r Receipt, amount money.Money) (Receipt, error) {
+	if amount.Sign() <= 0 {
+		return r, c.fail("refund", ErrInvalidAmount)
+	}
+	refunded, err := r.Refunded.Add(amount)
+	if err != nil {
+		return r, c.fail("refund", err)
+	}
+	if err := c.gateway.Refund(r.Transaction, amount); err != nil {
+		return r, c.fail("refund", err)
+	}
+	r.Refunded = refunded
+	return r, nil
+}
+
+// DebitCardPM represents the debit card payment method.
+type DebitCardPM struct {
+	cardPM
+}
+
+// NewDebitCard returns a debit card payment method charging o.Account
+// through o.Gateway.
+func NewDebitCard(o Options) (*DebitCardPM, error) {
+	c, err := newCard("debit card", o)
+	if err != nil {
+		return nil, err
+	}
+	return &DebitCardPM{c}, nil
+}
+
+// CreditCardPM represents the credit card payment method.
+type CreditCardPM struct {
+	cardPM
+}
+
+// NewCreditCard returns a credit card payment method charging o.Account
+// through o.Gateway.
+func NewCreditCard(o Options) (*CreditCardPM, error) {
+	c, err := newCard("credit card", o)
+	if err != nil {
+		return nil, err
+	}
+	return &CreditCardPM{c}, nil
+}
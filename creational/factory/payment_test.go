@@ -0,0 +1,154 @@
+package factory
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
+
+func eur(s string) money.Money {
+	return money.MustParse(s, "EUR")
+}
+
+func newTestCard(t *testing.T, balance string) (*DebitCardPM, *FakeGateway) {
+	t.Helper()
+	gw := NewFakeGateway()
+	gw.Open("alice", eur(balance))
+	pm, err := New("debit_card", Options{Gateway: gw, Account: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pm.(*DebitCardPM), gw
+}
+
+func TestRegister(t *testing.T) {
+	err := Register("voucher", func(Options) (PaymentMethod, error) {
+		return new(CashPM), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(Names(), "voucher") {
+		t.Errorf("The voucher method is not listed in %v", Names())
+	}
+	if _, err := New("voucher", Options{}); err != nil {
+		t.Error(err)
+	}
+	if err := Register("cash", func(Options) (PaymentMethod, error) { return nil, nil }); err == nil {
+		t.Error("Registering a taken name must fail")
+	}
+	if err := Register("nothing", nil); err == nil {
+		t.Error("Registering a nil constructor must fail")
+	}
+	if _, err := New("bitcoin", Options{}); err == nil {
+		t.Error("An unknown payment method must return an error")
+	}
+	if _, err := New("credit_card", Options{}); err == nil {
+		t.Error("A card without a gateway must return an error")
+	}
+}
+
+func TestCard_Pay(t *testing.T) {
+	card, gw := newTestCard(t, "50.00")
+	r, err := card.Pay(eur("30.10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != "30.10 EUR paid using debit card\n" || r.Transaction == "" {
+		t.Errorf("Unexpected receipt %+v", r)
+	}
+	if b := gw.Balance("alice"); b != eur("19.90") {
+		t.Errorf("Expected a balance of 19.90, got %s", b)
+	}
+
+	_, err = card.Pay(eur("20"))
+	var pe *PaymentError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrInsufficientFunds) || pe.Step != "authorization" {
+		t.Errorf("Expected an insufficient funds authorization error, got %v", err)
+	}
+	if _, err := card.Pay(eur("-1")); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+	if _, err := card.Pay(money.MustNew(100, "USD")); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestCard_Declined(t *testing.T) {
+	card, gw := newTestCard(t, "50.00")
+	gw.Decline("alice", "stolen card")
+	_, err := card.Pay(eur("1"))
+	if !errors.Is(err, ErrDeclined) {
+		t.Errorf("Expected ErrDeclined, got %v", err)
+	}
+	if err.Error() != "debit card authorization failed: declined: stolen card" {
+		t.Errorf("Unexpected message %q", err)
+	}
+}
+
+func TestCard_CaptureFails(t *testing.T) {
+	card, gw := newTestCard(t, "50.00")
+	errDown := errors.New("processor down")
+	gw.FailCaptures("alice", errDown)
+	_, err := card.Pay(eur("20"))
+	var pe *PaymentError
+	if !errors.As(err, &pe) || !errors.Is(err, errDown) || pe.Step != "capture" {
+		t.Errorf("Expected a capture error, got %v", err)
+	}
+	if gw.Available("alice") != eur("50") || gw.Balance("alice") != eur("50") {
+		t.Error("A failed capture must void its authorization")
+	}
+}
+
+func TestCard_AuthorizeCaptureVoid(t *testing.T) {
+	card, gw := newTestCard(t, "50.00")
+	a, err := card.Authorize(eur("40"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gw.Available("alice") != eur("10") || gw.Balance("alice") != eur("50") {
+		t.Error("An authorization must hold the amount without charging it")
+	}
+	if err := card.Void(a); err != nil {
+		t.Fatal(err)
+	}
+	if gw.Available("alice") != eur("50") {
+		t.Error("Voiding must release the held amount")
+	}
+	if _, err := card.Capture(a); !errors.Is(err, ErrUnknownAuthorization) {
+		t.Errorf("A voided authorization can't be captured, got %v", err)
+	}
+}
+
+func TestCard_Refund(t *testing.T) {
+	card, gw := newTestCard(t, "50.00")
+	r, err := card.Pay(eur("50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = card.Refund(r, eur("20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Refunded != eur("20") || gw.Balance("alice") != eur("20") {
+		t.Errorf("Unexpected refund state %+v, balance %s", r, gw.Balance("alice"))
+	}
+	if _, err := card.Refund(r, eur("30.01")); !errors.Is(err, ErrRefundExceeded) {
+		t.Errorf("Expected ErrRefundExceeded, got %v", err)
+	}
+	if _, err := card.Refund(Receipt{Transaction: "tx-missing"}, eur("1")); !errors.Is(err, ErrUnknownTransaction) {
+		t.Errorf("Expected ErrUnknownTransaction, got %v", err)
+	}
+}
+
+func TestGetPaymentMethod_CreditCard(t *testing.T) {
+	pm, err := GetPaymentMethod(CreditCard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pm.(CardPaymentMethod); !ok {
+		t.Error("The credit card must support the two step flow")
+	}
+}
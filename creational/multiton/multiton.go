@@ -0,0 +1,226 @@
+// Package multiton keeps one instance per key, such as one client per tenant
+// or one pool per database. Instances are created lazily, evicted when idle
+// for too long and closed on eviction and shutdown.
+package multiton
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/antoniofmoliveira/patterns/creational/singleton"
+)
+
+// ErrClosed is returned by Get after the registry was closed.
+var ErrClosed = errors.New("multiton: registry closed")
+
+// Options configures a Registry. The zero value never evicts.
+type Options[K comparable, V any] struct {
+	// TTL is how long an instance can stay unused before being evicted.
+	TTL time.Duration
+	// JanitorInterval is how often idle instances are looked for in the
+	// background. When zero, eviction only happens on EvictIdle calls.
+	JanitorInterval time.Duration
+	// OnEvict is called for every instance removed by eviction or Close,
+	// before the instance is closed.
+	OnEvict func(key K, value V)
+	// Now is the clock, time.Now when nil. Tests can replace it.
+	Now func() time.Time
+}
+
+// Stats are the registry metrics.
+type Stats struct {
+	Live       int
+	Created    uint64
+	Evicted    uint64
+	InitErrors uint64
+}
+
+type entry[V any] struct {
+	lazy     *singleton.Lazy[V]
+	lastUsed time.Time
+	// pending counts the Get calls waiting on lazy; the entry is still
+	// initializing while it is positive.
+	pending int
+	// released is set once the instance was handed to release.
+	released bool
+}
+
+// Registry holds one lazily created instance per key. It is safe for
+// concurrent use: the initializer runs once per key, and initializing a key
+// doesn't block Get on other keys. Instances implementing io.Closer are
+// closed when they are evicted and when the registry is closed.
+type Registry[K comparable, V any] struct {
+	init func(K) (V, error)
+	opts Options[K, V]
+
+	mu      sync.Mutex
+	entries map[K]*entry[V]
+	stats   Stats
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New returns a registry that creates the instance of a key with init.
+func New[K comparable, V any](init func(K) (V, error), opts Options[K, V]) *Registry[K, V] {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	r := &Registry[K, V]{init: init, opts: opts, entries: make(map[K]*entry[V])}
+	if opts.TTL > 0 && opts.JanitorInterval > 0 {
+		r.stop = make(chan struct{})
+		r.done = make(chan struct{})
+		go r.janitor()
+	}
+	return r
+}
+
+// Get returns the instance of key, creating it when needed. If the
+// initializer fails, the error is returned and the next Get retries. An
+// instance that finishes initializing after Close is closed right away and
+// Get returns ErrClosed.
+func (r *Registry[K, V]) Get(key K) (V, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		var zero V
+		return zero, ErrClosed
+	}
+	e, ok := r.entries[key]
+	if !ok {
+		e = &entry[V]{lazy: singleton.NewLazy(func() (V, error) {
+			v, err := r.init(key)
+			r.mu.Lock()
+			if err != nil {
+				r.stats.InitErrors++
+			} else {
+				r.stats.Created++
+			}
+			r.mu.Unlock()
+			return v, err
+		})}
+		r.entries[key] = e
+	}
+	e.lastUsed = r.opts.Now()
+	e.pending++
+	r.mu.Unlock()
+
+	v, err := e.lazy.Get()
+	r.mu.Lock()
+	e.pending--
+	if err == nil && r.closed {
+		release := !e.released
+		e.released = true
+		r.mu.Unlock()
+		if release {
+			r.release([]K{key}, []V{v})
+		}
+		var zero V
+		return zero, ErrClosed
+	}
+	r.mu.Unlock()
+	return v, err
+}
+
+// EvictIdle evicts the instances unused for longer than the TTL and returns
+// how many were evicted. Instances still being created are never evicted,
+// and keys whose initializer failed are forgotten.
+func (r *Registry[K, V]) EvictIdle() int {
+	if r.opts.TTL <= 0 {
+		return 0
+	}
+	r.mu.Lock()
+	now := r.opts.Now()
+	var evicted []K
+	var values []V
+	for k, e := range r.entries {
+		if now.Sub(e.lastUsed) < r.opts.TTL || e.pending > 0 {
+			continue
+		}
+		if !e.lazy.Initialized() {
+			delete(r.entries, k)
+			continue
+		}
+		e.released = true
+		evicted = append(evicted, k)
+		values = append(values, e.lazy.MustGet())
+		delete(r.entries, k)
+	}
+	r.stats.Evicted += uint64(len(evicted))
+	r.mu.Unlock()
+	r.release(evicted, values)
+	return len(evicted)
+}
+
+// Stats returns a snapshot of the registry metrics.
+func (r *Registry[K, V]) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	for _, e := range r.entries {
+		if e.lazy.Initialized() {
+			s.Live++
+		}
+	}
+	return s
+}
+
+// Close stops the janitor and closes every instance. Get fails with
+// ErrClosed afterwards. The first error returned by an instance Close is
+// reported, but every instance is closed anyway.
+func (r *Registry[K, V]) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	var keys []K
+	var values []V
+	for k, e := range r.entries {
+		if e.lazy.Initialized() {
+			e.released = true
+			keys = append(keys, k)
+			values = append(values, e.lazy.MustGet())
+		}
+	}
+	r.entries = make(map[K]*entry[V])
+	r.mu.Unlock()
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+	}
+	return r.release(keys, values)
+}
+
+// release calls the eviction hook and closes the given instances.
+func (r *Registry[K, V]) release(keys []K, values []V) error {
+	var first error
+	for i, v := range values {
+		if r.opts.OnEvict != nil {
+			r.opts.OnEvict(keys[i], v)
+		}
+		if c, ok := any(v).(io.Closer); ok {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (r *Registry[K, V]) janitor() {
+	defer close(r.done)
+	t := time.NewTicker(r.opts.JanitorInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-t.C:
+			r.EvictIdle()
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package multiton
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type conn struct {
+	tenant string
+	closed atomic.Bool
+}
+
+func (c *conn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRegistry_OneInstancePerKey(t *testing.T) {
+	var calls atomic.Int32
+	r := New(func(tenant string) (*conn, error) {
+		calls.Add(1)
+		return &conn{tenant: tenant}, nil
+	}, Options[string, *conn]{})
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tenant := fmt.Sprintf("tenant-%d", i%5)
+			c, err := r.Get(tenant)
+			if err != nil || c.tenant != tenant {
+				t.Errorf("Unexpected instance %v, %v", c, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if calls.Load() != 5 {
+		t.Errorf("Expected 5 instances created, got %d", calls.Load())
+	}
+	a1, _ := r.Get("tenant-1")
+	a2, _ := r.Get("tenant-1")
+	if a1 != a2 {
+		t.Error("The same key must return the same instance")
+	}
+	if s := r.Stats(); s.Live != 5 || s.Created != 5 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestRegistry_InitError(t *testing.T) {
+	fail := true
+	r := New(func(key int) (int, error) {
+		if fail {
+			return 0, errors.New("not yet")
+		}
+		return key * 10, nil
+	}, Options[int, int]{})
+	if _, err := r.Get(1); err == nil {
+		t.Fatal("Expected the initializer error")
+	}
+	fail = false
+	if v, err := r.Get(1); err != nil || v != 10 {
+		t.Errorf("Expected a retry to succeed, got %d, %v", v, err)
+	}
+	if s := r.Stats(); s.InitErrors != 1 || s.Created != 1 || s.Live != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestRegistry_EvictIdle(t *testing.T) {
+	clk := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var evicted []string
+	r := New(func(tenant string) (*conn, error) {
+		return &conn{tenant: tenant}, nil
+	}, Options[string, *conn]{
+		TTL:     time.Minute,
+		Now:     clk.Now,
+		OnEvict: func(k string, _ *conn) { evicted = append(evicted, k) },
+	})
+	a, _ := r.Get("a")
+	b, _ := r.Get("b")
+	clk.Advance(40 * time.Second)
+	r.Get("b")
+	clk.Advance(30 * time.Second)
+
+	if n := r.EvictIdle(); n != 1 {
+		t.Fatalf("Expected 1 eviction, got %d", n)
+	}
+	if !a.closed.Load() || b.closed.Load() {
+		t.Error("Only the idle instance must be closed")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Errorf("Unexpected eviction hook calls %v", evicted)
+	}
+	a2, _ := r.Get("a")
+	if a2 == a {
+		t.Error("An evicted key must get a new instance")
+	}
+	if s := r.Stats(); s.Live != 2 || s.Evicted != 1 || s.Created != 3 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestRegistry_Janitor(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	r := New(func(tenant string) (*conn, error) {
+		return &conn{tenant: tenant}, nil
+	}, Options[string, *conn]{TTL: time.Minute, JanitorInterval: time.Millisecond, Now: clk.Now})
+	defer r.Close()
+	c, _ := r.Get("a")
+	clk.Advance(2 * time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for !c.closed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("The janitor didn't evict the idle instance")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRegistry_Close(t *testing.T) {
+	r := New(func(tenant string) (*conn, error) {
+		return &conn{tenant: tenant}, nil
+	}, Options[string, *conn]{TTL: time.Hour, JanitorInterval: time.Hour})
+	a, _ := r.Get("a")
+	b, _ := r.Get("b")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.closed.Load() || !b.closed.Load() {
+		t.Error("Close must close every instance")
+	}
+	if _, err := r.Get("a"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error("Closing twice must be harmless")
+	}
+	if s := r.Stats(); s.Live != 0 {
+		t.Errorf("Expected no live instances, got %d", s.Live)
+	}
+}
+
+func TestRegistry_SlowInit(t *testing.T) {
+	clk := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	started, proceed := make(chan struct{}), make(chan struct{})
+	var created *conn
+	r := New(func(tenant string) (*conn, error) {
+		close(started)
+		<-proceed
+		created = &conn{tenant: tenant}
+		return created, nil
+	}, Options[string, *conn]{TTL: time.Minute, Now: clk.Now})
+
+	errs := make(chan error)
+	go func() {
+		_, err := r.Get("a")
+		errs <- err
+	}()
+	<-started
+	clk.Advance(2 * time.Minute)
+	if n := r.EvictIdle(); n != 0 {
+		t.Errorf("An initializing instance must not be evicted, got %d evictions", n)
+	}
+	r.mu.Lock()
+	_, kept := r.entries["a"]
+	r.mu.Unlock()
+	if !kept {
+		t.Error("An initializing entry must be kept by EvictIdle")
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(proceed)
+	if err := <-errs; !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if !created.closed.Load() {
+		t.Error("An instance created after Close must be closed")
+	}
+}
@@ -0,0 +1,147 @@
+package prototype
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// Slice is a copy-on-write slice for large prototypes, such as photos.
+// Clone is O(1): the clone shares the data of the original. The data is
+// split in pages, and writing through Set or CopyIn copies only the pages
+// written, and only when they are shared.
+//
+// Copy a Slice with Clone, not with an assignment, which would let both
+// copies write to the same pages. A Slice must not be used by several
+// goroutines at once, but Slices sharing data can be used concurrently. The
+// zero value is an empty slice.
+type Slice[E any] struct {
+	t *pageTable[E]
+}
+
+type pageTable[E any] struct {
+	refs    atomic.Int32 // Slices using the table
+	pageLen int
+	length  int
+	pages   []*page[E]
+}
+
+type page[E any] struct {
+	refs atomic.Int32 // tables using the page
+	data []E
+}
+
+// pageBytes is the default size of a page.
+const pageBytes = 4096
+
+// NewSlice returns a Slice holding a copy of data, with pages of pageLen
+// elements, or about 4 KiB when pageLen is 0.
+func NewSlice[E any](data []E, pageLen int) Slice[E] {
+	if pageLen <= 0 {
+		pageLen = max(1, pageBytes/max(1, int(reflect.TypeFor[E]().Size())))
+	}
+	t := &pageTable[E]{pageLen: pageLen, length: len(data)}
+	t.refs.Store(1)
+	for off := 0; off < len(data); off += pageLen {
+		p := &page[E]{data: append([]E(nil), data[off:min(off+pageLen, len(data))]...)}
+		p.refs.Store(1)
+		t.pages = append(t.pages, p)
+	}
+	return Slice[E]{t: t}
+}
+
+func (s Slice[E]) Len() int {
+	if s.t == nil {
+		return 0
+	}
+	return s.t.length
+}
+
+// At returns the element at index i.
+func (s Slice[E]) At(i int) E {
+	s.check(i, 1)
+	return s.t.pages[i/s.t.pageLen].data[i%s.t.pageLen]
+}
+
+// Clone returns a Slice sharing the data of s until either is written.
+func (s Slice[E]) Clone() Slice[E] {
+	if s.t != nil {
+		s.t.refs.Add(1)
+	}
+	return s
+}
+
+// Set writes v at index i.
+func (s *Slice[E]) Set(i int, v E) {
+	s.check(i, 1)
+	s.own(i / s.t.pageLen).data[i%s.t.pageLen] = v
+}
+
+// CopyIn writes src from index off on, copying only the pages it touches.
+func (s *Slice[E]) CopyIn(off int, src []E) {
+	s.check(off, len(src))
+	for len(src) > 0 {
+		p := s.own(off / s.t.pageLen)
+		n := copy(p.data[off%s.t.pageLen:], src)
+		off += n
+		src = src[n:]
+	}
+}
+
+// Copy returns the elements in a new, unshared slice.
+func (s Slice[E]) Copy() []E {
+	out := make([]E, 0, s.Len())
+	if s.t != nil {
+		for _, p := range s.t.pages {
+			out = append(out, p.data...)
+		}
+	}
+	return out
+}
+
+// SharedPages returns how many pages of s are shared with other Slices.
+func (s Slice[E]) SharedPages() int {
+	if s.t == nil {
+		return 0
+	}
+	if s.t.refs.Load() > 1 {
+		return len(s.t.pages)
+	}
+	n := 0
+	for _, p := range s.t.pages {
+		if p.refs.Load() > 1 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s Slice[E]) check(off, n int) {
+	if off < 0 || n < 0 || off+n > s.Len() || (n == 0 && off > s.Len()) {
+		panic(fmt.Sprintf("prototype.Slice: range [%d:%d] out of range with length %d", off, off+n, s.Len()))
+	}
+}
+
+// own makes s the only user of its page table and of page i, copying them
+// when shared, and returns the page.
+func (s *Slice[E]) own(i int) *page[E] {
+	t := s.t
+	if t.refs.Load() > 1 {
+		nt := &pageTable[E]{pageLen: t.pageLen, length: t.length, pages: append([]*page[E](nil), t.pages...)}
+		nt.refs.Store(1)
+		for _, p := range nt.pages {
+			p.refs.Add(1)
+		}
+		t.refs.Add(-1)
+		s.t, t = nt, nt
+	}
+	p := t.pages[i]
+	if p.refs.Load() > 1 {
+		np := &page[E]{data: append([]E(nil), p.data...)}
+		np.refs.Store(1)
+		p.refs.Add(-1)
+		t.pages[i] = np
+		p = np
+	}
+	return p
+}
@@ -0,0 +1,106 @@
+package prototype
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// ErrOutOfStock is returned when reserving more than is available.
+var ErrOutOfStock = errors.New("out of stock")
+
+// StockLevel is the stock of one prototype. Reserved units are part of
+// OnHand but can't be reserved again.
+type StockLevel struct {
+	OnHand   int
+	Reserved int
+}
+
+func (l StockLevel) Available() int {
+	return l.OnHand - l.Reserved
+}
+
+// Inventory tracks the stock of every prototype. It is safe for concurrent
+// use.
+type Inventory[K comparable] struct {
+	mu     sync.Mutex
+	levels map[K]StockLevel
+}
+
+func NewInventory[K comparable]() *Inventory[K] {
+	return &Inventory[K]{levels: make(map[K]StockLevel)}
+}
+
+// Restock adds n units to the stock of key.
+func (inv *Inventory[K]) Restock(key K, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("restock of %v: quantity must be positive, got %d", key, n)
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	l := inv.levels[key]
+	l.OnHand += n
+	inv.levels[key] = l
+	return nil
+}
+
+// Reserve sets n units aside, failing with ErrOutOfStock when fewer are
+// available.
+func (inv *Inventory[K]) Reserve(key K, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("reserve of %v: quantity must be positive, got %d", key, n)
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	l := inv.levels[key]
+	if l.Available() < n {
+		return fmt.Errorf("%w: %v has %d available, %d requested", ErrOutOfStock, key, l.Available(), n)
+	}
+	l.Reserved += n
+	inv.levels[key] = l
+	return nil
+}
+
+// Release makes n reserved units available again, as when an order is
+// cancelled.
+func (inv *Inventory[K]) Release(key K, n int) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	l := inv.levels[key]
+	if n <= 0 || n > l.Reserved {
+		return fmt.Errorf("release of %d %v: %d reserved", n, key, l.Reserved)
+	}
+	l.Reserved -= n
+	inv.levels[key] = l
+	return nil
+}
+
+// Ship takes n reserved units out of the stock, as when an order leaves the
+// warehouse.
+func (inv *Inventory[K]) Ship(key K, n int) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	l := inv.levels[key]
+	if n <= 0 || n > l.Reserved {
+		return fmt.Errorf("ship of %d %v: %d reserved", n, key, l.Reserved)
+	}
+	l.Reserved -= n
+	l.OnHand -= n
+	inv.levels[key] = l
+	return nil
+}
+
+// Level returns the stock of key.
+func (inv *Inventory[K]) Level(key K) StockLevel {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.levels[key]
+}
+
+// Levels returns the stock of every key that was ever restocked.
+func (inv *Inventory[K]) Levels() map[K]StockLevel {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return maps.Clone(inv.levels)
+}
@@ -1,8 +1,12 @@
 package prototype
 
 import (
-	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/antoniofmoliveira/patterns/money"
 )
 
 // `ShirtCloner` is an interface that defines a contract for cloning shirts.
@@ -18,18 +22,26 @@ type ItemInfoGetter interface {
 	GetInfo() string
 }
 
-// `ShirtsCache` is a struct that implements the `ShirtCloner` interface.
-type ShirtsCache struct{}
+// `ShirtsCache` is a struct that implements the `ShirtCloner` interface. It clones the prototypes of `Prototypes`, or of `Shirts` when it is nil.
+// When `SKUs` is set every clone gets a new SKU, and when `Inventory` is set every clone reserves a unit of its prototype, to be given back with `Release`.
+type ShirtsCache struct {
+	Prototypes *PrototypeRegistry[ShirtColor, *Shirt]
+	SKUs       *SKUGenerator
+	Inventory  *Inventory[ShirtColor]
+}
 
 // `ShirtColor` is an alias for a byte type.
 type ShirtColor byte
 
-// This struct definition creates a `Shirt` struct with three fields: `Price` (a float32 representing the price of the shirt), `SKU` (a string representing the stock keeping unit of the shirt), and `Color` (a custom type `ShirtColor` representing the color of the shirt). 
+// This struct definition creates a `Shirt` struct with three fields: `Price` (an exact `money.Money` amount representing the price of the shirt), `SKU` (a string representing the stock keeping unit of the shirt), and `Color` (a custom type `ShirtColor` representing the color of the shirt).
 // The `Shirt` struct does not have any methods defined in this code snippet. It is a simple data structure used to store information about a shirt.
+// Sizes and Attributes are copied by Clone, so clones can change them freely.
 type Shirt struct {
-	Price float32
-	SKU   string
-	Color ShirtColor
+	Price      money.Money       `json:"price"`
+	SKU        string            `json:"sku"`
+	Color      ShirtColor        `json:"color"`
+	Sizes      []string          `json:"sizes,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 // `ShirtColor` is a custom type that represents the color of a shirt. It has three possible values: `White`, `Black`, and `Blue`.
@@ -39,23 +51,37 @@ const (
 	Blue  = 3
 )
 
-// simulates the database
+// The initial prototypes of `Shirts`.
 var whitePrototype *Shirt = &Shirt{
-	Price: 15.00,
+	Price: money.MustParse("15.00", "EUR"),
 	SKU:   "empty",
+	Sizes: []string{"S", "M", "L", "XL"},
 	Color: White,
 }
 var blackPrototype *Shirt = &Shirt{
-	Price: 16.00,
+	Price: money.MustParse("16.00", "EUR"),
 	SKU:   "empty",
+	Sizes: []string{"S", "M", "L", "XL"},
 	Color: Black,
 }
 var bluePrototype *Shirt = &Shirt{
-	Price: 17.00,
+	Price: money.MustParse("17.00", "EUR"),
 	SKU:   "empty",
+	Sizes: []string{"S", "M", "L", "XL"},
 	Color: Blue,
 }
 
+// Shirts is the registry of shirt prototypes by color, used by `ShirtsCache`. Prototypes can be added, updated or loaded from JSON at runtime.
+var Shirts = NewPrototypeRegistry[ShirtColor, *Shirt]()
+
+func init() {
+	for _, p := range []*Shirt{whitePrototype, blackPrototype, bluePrototype} {
+		if err := Shirts.Register(p.Color, p); err != nil {
+			panic(err)
+		}
+	}
+}
+
 // GetShirtsCloner returns a new instance of a `ShirtCloner`, which is a struct that implements the `ShirtCloner` interface. It is used to clone prototype shirts.
 func GetShirtsCloner() ShirtCloner {
 	shirtsCache := new(ShirtsCache)
@@ -64,27 +90,59 @@ func GetShirtsCloner() ShirtCloner {
 
 // GetClone returns a new instance of the requested shirt, or an error if the requested model is not recognized.
 func (s *ShirtsCache) GetClone(m int) (ItemInfoGetter, error) {
-	switch m {
-	case White:
-		newItem := *whitePrototype
-		return &newItem, nil
-	case Black:
-		newItem := *blackPrototype
-		return &newItem, nil
-	case Blue:
-		newItem := *bluePrototype
-		return &newItem, nil
-	default:
-		return nil, errors.New("Shirt model not recognized")
+	if m < 0 || m > math.MaxUint8 {
+		return nil, fmt.Errorf("shirt model not recognized: %w: %d", ErrPrototypeNotFound, m)
+	}
+	shirt, err := s.Clone(ShirtColor(m))
+	if err != nil {
+		return nil, err
+	}
+	return shirt, nil
+}
+
+// Clone is like `GetClone` but returns the concrete `*Shirt`.
+func (s *ShirtsCache) Clone(c ShirtColor) (*Shirt, error) {
+	prototypes := s.Prototypes
+	if prototypes == nil {
+		prototypes = Shirts
+	}
+	shirt, err := prototypes.Clone(c)
+	if err != nil {
+		return nil, fmt.Errorf("shirt model not recognized: %w", err)
+	}
+	if s.Inventory != nil {
+		if err := s.Inventory.Reserve(c, 1); err != nil {
+			return nil, err
+		}
 	}
+	if s.SKUs != nil {
+		shirt.SKU = s.SKUs.Next(c.Code())
+	}
+	return shirt, nil
+}
+
+// Release gives back the unit reserved for a clone that won't be sold.
+func (s *ShirtsCache) Release(shirt *Shirt) error {
+	if s.Inventory == nil {
+		return nil
+	}
+	return s.Inventory.Release(shirt.Color, 1)
+}
+
+// Clone returns a deep copy of the shirt.
+func (s *Shirt) Clone() *Shirt {
+	c := *s
+	c.Sizes = slices.Clone(s.Sizes)
+	c.Attributes = maps.Clone(s.Attributes)
+	return &c
 }
 
 // GetInfo returns a string representation of the shirt, including its SKU, color, and price.
 func (s *Shirt) GetInfo() string {
-	return fmt.Sprintf("Shirt with SKU '%s' and Color id %d that costs %f\n", s.SKU, s.Color, s.GetPrice())
+	return fmt.Sprintf("Shirt with SKU '%s' and Color id %d that costs %s\n", s.SKU, s.Color, s.GetPrice())
 }
 
 // GetPrice returns the price of the shirt.
-func (i *Shirt) GetPrice() float32 {
+func (i *Shirt) GetPrice() money.Money {
 	return i.Price
 }
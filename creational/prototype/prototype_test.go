@@ -1,6 +1,18 @@
 package prototype
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/patterns/money"
+)
 
 // This is a Go test function named `TestClone` that tests the cloning functionality of a shirt cache. Here's a succinct explanation:
 // 1. It retrieves a shirt cache using `GetShirtsCloner()` and checks if it's not nil.
@@ -66,4 +78,342 @@ func TestClone(t *testing.T) {
 	if err == nil {
 		t.Fatal("An error must be returned for an invalid model")
 	}
+	for _, m := range []int{-1, 256 + int(White), 256 + int(Black)} {
+		if _, err := shirtCache.GetClone(m); !errors.Is(err, ErrPrototypeNotFound) {
+			t.Errorf("GetClone(%d) = %v", m, err)
+		}
+	}
+}
+
+func TestDeepClone(t *testing.T) {
+	cache := &ShirtsCache{}
+	s1, err := cache.Clone(White)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Sizes[0] = "XXS"
+	s1.Attributes = map[string]string{"print": "logo"}
+	s2, _ := cache.Clone(White)
+	if s2.Sizes[0] != "S" || s2.Attributes != nil {
+		t.Errorf("clones share state: %+v", s2)
+	}
+	if _, err := cache.Clone(10); !errors.Is(err, ErrPrototypeNotFound) {
+		t.Errorf("Clone(10) = %v", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewPrototypeRegistry[string, *Shirt]()
+	proto := &Shirt{Price: money.MustParse("20", "EUR"), Color: 9, Sizes: []string{"M"}}
+	if err := r.Register("green", proto); err != nil {
+		t.Fatal(err)
+	}
+	proto.Sizes[0] = "changed"
+	if err := r.Register("green", proto); !errors.Is(err, ErrPrototypeExists) {
+		t.Errorf("duplicate Register() = %v", err)
+	}
+	s, err := r.Clone("green")
+	if err != nil || s.Sizes[0] != "M" {
+		t.Errorf("registry kept the caller's prototype: %+v, %v", s, err)
+	}
+	proto.Price = money.MustParse("25", "EUR")
+	if err := r.Update("green", proto); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := r.Clone("green"); s.Price != proto.Price {
+		t.Errorf("price after Update() = %s", s.Price)
+	}
+	if err := r.Register("nil", nil); !errors.Is(err, ErrNilPrototype) {
+		t.Errorf("Register() of nil = %v", err)
+	}
+	if err := r.Update("green", nil); !errors.Is(err, ErrNilPrototype) {
+		t.Errorf("Update() to nil = %v", err)
+	}
+	if err := r.Update("red", proto); !errors.Is(err, ErrPrototypeNotFound) {
+		t.Errorf("Update() of missing = %v", err)
+	}
+	if err := r.Remove("green"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove("green"); !errors.Is(err, ErrPrototypeNotFound) {
+		t.Errorf("second Remove() = %v", err)
+	}
+	if len(r.Keys()) != 0 {
+		t.Errorf("keys = %v", r.Keys())
+	}
+}
+
+func TestRegistryJSON(t *testing.T) {
+	r := NewPrototypeRegistry[ShirtColor, *Shirt]()
+	if err := r.LoadFile("testdata/shirts.json"); err != nil {
+		t.Fatal(err)
+	}
+	cache := &ShirtsCache{Prototypes: r}
+	linen, err := cache.Clone(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linen.Price != money.MustParse("21.50", "EUR") || linen.Attributes["fabric"] != "linen" {
+		t.Errorf("unexpected shirt %+v", linen)
+	}
+	if _, err := cache.Clone(Black); err == nil {
+		t.Error("LoadFile() didn't replace the prototypes")
+	}
+
+	var buf bytes.Buffer
+	if err := r.SaveJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	again := NewPrototypeRegistry[ShirtColor, *Shirt]()
+	if err := again.LoadJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := again.Clone(4); !reflect.DeepEqual(s, linen) {
+		t.Errorf("round trip = %+v, want %+v", s, linen)
+	}
+
+	for _, bad := range []string{`null`, `{"1": null}`, `{"1": {"colour": 1}}`, `{"x": {}}`, `[`} {
+		if err := again.LoadJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s accepted", bad)
+		}
+	}
+	if _, err := again.Clone(4); err != nil {
+		t.Error("failed load changed the prototypes")
+	}
+}
+
+func TestRegistryConcurrentUpdate(t *testing.T) {
+	r := NewPrototypeRegistry[int, *Shirt]()
+	r.Register(1, &Shirt{Sizes: []string{"M"}, Attributes: map[string]string{"v": "0"}})
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				if w == 0 {
+					r.Update(1, &Shirt{Sizes: []string{"M"}, Attributes: map[string]string{"v": fmt.Sprint(i)}})
+					continue
+				}
+				s, err := r.Clone(1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				s.Attributes["mine"] = "yes"
+				s.Sizes[0] = "L"
+			}
+		}()
+	}
+	wg.Wait()
+	if s, _ := r.Clone(1); s.Attributes["mine"] != "" || s.Sizes[0] != "M" {
+		t.Errorf("clones changed the prototype: %+v", s)
+	}
+}
+
+func TestSKUGenerator(t *testing.T) {
+	g, err := NewSKUGenerator(DefaultSKUPattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	year := 2026
+	g.Now = func() time.Time { return time.Date(year, 5, 1, 0, 0, 0, 0, time.UTC) }
+	for _, want := range []string{"WHT-2026-000001", "WHT-2026-000002"} {
+		if got := g.Next(ShirtColor(White).Code()); got != want {
+			t.Errorf("Next() = %s, want %s", got, want)
+		}
+	}
+	if got := g.Next("BLK"); got != "BLK-2026-000001" {
+		t.Errorf("Next(BLK) = %s", got)
+	}
+	year = 2027
+	if got := g.Next("WHT"); got != "WHT-2027-000001" {
+		t.Errorf("Next() in a new year = %s", got)
+	}
+	g.Seed("BLU", 2027, 41)
+	g.Seed("WHT", 2027, 0)
+	if got := g.Next("BLU"); got != "BLU-2027-000042" {
+		t.Errorf("Next() after Seed = %s", got)
+	}
+	if got := g.Next("WHT"); got != "WHT-2027-000002" {
+		t.Errorf("Seed moved a sequence backwards: %s", got)
+	}
+
+	short, _ := NewSKUGenerator("S{seq:2}")
+	for range 99 {
+		short.Next("")
+	}
+	if got := short.Next(""); got != "S100" {
+		t.Errorf("sequence past the width = %s", got)
+	}
+
+	sep, err := NewSKUGenerator("{seq}-{code}-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		sep.Next("A")
+	}
+	if a, b := sep.Next("A"), sep.Next("1A"); a == b {
+		t.Errorf("A with sequence 11 and 1A with sequence 1 both give %s", a)
+	}
+
+	for _, bad := range []string{"{code}-{year}", "{seq}{seq}", "{seq:0}", "{seq:x}", "{color}-{seq}", "{code:3}-{seq}", "{seq", "{code}{seq}", "{seq}-{code}", "{code}{year}-{seq}", "{seq}{code}-", "{year}{code}-{seq}"} {
+		if _, err := NewSKUGenerator(bad); err == nil {
+			t.Errorf("pattern %q accepted", bad)
+		}
+	}
+}
+
+func TestInventory(t *testing.T) {
+	inv := NewInventory[ShirtColor]()
+	inv.Restock(White, 2)
+	if err := inv.Reserve(White, 3); !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("Reserve() over stock = %v", err)
+	}
+	if err := inv.Reserve(White, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Release(White, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Ship(White, 1); err != nil {
+		t.Fatal(err)
+	}
+	if l := inv.Level(White); l != (StockLevel{OnHand: 1}) || l.Available() != 1 {
+		t.Errorf("level = %+v", l)
+	}
+	if inv.Release(White, 1) == nil || inv.Ship(White, 1) == nil || inv.Restock(White, 0) == nil || inv.Reserve(Black, 0) == nil {
+		t.Error("invalid quantity accepted")
+	}
+	if got := inv.Levels(); len(got) != 1 || got[White].OnHand != 1 {
+		t.Errorf("levels = %v", got)
+	}
+}
+
+func TestConcurrentStockedClones(t *testing.T) {
+	skus, _ := NewSKUGenerator(DefaultSKUPattern)
+	cache := &ShirtsCache{SKUs: skus, Inventory: NewInventory[ShirtColor]()}
+	cache.Inventory.Restock(White, 500)
+	cache.Inventory.Restock(Blue, 100)
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var outOfStock atomic.Int64
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				color := ShirtColor(White)
+				if i%2 == 1 {
+					color = Blue
+				}
+				s, err := cache.Clone(color)
+				if errors.Is(err, ErrOutOfStock) {
+					outOfStock.Add(1)
+					continue
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[s.SKU] {
+					t.Errorf("duplicate SKU %s", s.SKU)
+				}
+				seen[s.SKU] = true
+				mu.Unlock()
+				if w == 0 && color == White {
+					cache.Release(s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	white, blue := cache.Inventory.Level(White), cache.Inventory.Level(Blue)
+	// worker 0 released its 50 white shirts
+	if white.Reserved != 350 || blue.Reserved != 100 {
+		t.Errorf("white %+v, blue %+v", white, blue)
+	}
+	if got := outOfStock.Load(); got != 300 {
+		t.Errorf("%d blue clones out of stock", got)
+	}
+}
+
+func TestCOWSlice(t *testing.T) {
+	data := make([]byte, 10_000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	orig := NewSlice(data, 1000)
+	data[0] = 99
+	if orig.At(0) != 0 || orig.Len() != 10_000 {
+		t.Fatal("NewSlice() didn't copy its data")
+	}
+	clone := orig.Clone()
+	if clone.SharedPages() != 10 {
+		t.Errorf("fresh clone shares %d pages", clone.SharedPages())
+	}
+	clone.Set(1500, 7)
+	clone.CopyIn(2990, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20})
+	if clone.At(1500) != 7 || clone.At(3009) != 20 || orig.At(1500) != byte(1500%256) || orig.At(3009) != byte(3009%256) {
+		t.Error("write leaked between clones")
+	}
+	if got := clone.SharedPages(); got != 7 {
+		t.Errorf("clone shares %d pages after writing 3, want 7", got)
+	}
+	if got := orig.SharedPages(); got != 7 {
+		t.Errorf("original shares %d pages, want 7", got)
+	}
+	orig.Set(0, 1)
+	if clone.At(0) != 0 || orig.SharedPages() != 6 {
+		t.Error("writing the original after cloning")
+	}
+	if c := clone.Copy(); len(c) != 10_000 || c[1500] != 7 {
+		t.Error("Copy() mismatch")
+	}
+
+	var zero Slice[int]
+	if zero.Len() != 0 || len(zero.Copy()) != 0 || zero.Clone().Len() != 0 {
+		t.Error("zero value is not empty")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("out of range write didn't panic")
+		}
+	}()
+	clone.CopyIn(9999, []byte{1, 2})
+}
+
+func TestCOWSliceConcurrentClones(t *testing.T) {
+	proto := NewSlice(make([]int, 5000), 100)
+	var wg sync.WaitGroup
+	clones := make([]Slice[int], 8)
+	for i := range clones {
+		clones[i] = proto.Clone()
+	}
+	for i := range clones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 5000 {
+				if j%(i+1) == 0 {
+					clones[i].Set(j, i+1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i, c := range clones {
+		if c.At(0) != i+1 || c.At(4999) != map[bool]int{true: i + 1, false: 0}[4999%(i+1) == 0] {
+			t.Errorf("clone %d corrupted", i)
+		}
+	}
+	for j := range 5000 {
+		if proto.At(j) != 0 {
+			t.Fatalf("prototype written at %d", j)
+		}
+	}
 }
@@ -0,0 +1,150 @@
+package prototype
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Cloner is a prototype able to make a deep copy of itself: the copy shares
+// no mutable state, such as slices or maps, with the original.
+type Cloner[T any] interface {
+	Clone() T
+}
+
+var (
+	ErrPrototypeExists   = errors.New("prototype already registered")
+	ErrPrototypeNotFound = errors.New("prototype not found")
+	ErrNilPrototype      = errors.New("nil prototype")
+)
+
+// PrototypeRegistry holds prototypes by key and hands out clones of them.
+// Prototypes can be registered, updated and removed while other goroutines
+// clone them. The registry keeps its own copies, so callers can't change a
+// prototype after handing it over.
+type PrototypeRegistry[K comparable, T Cloner[T]] struct {
+	mu     sync.RWMutex
+	protos map[K]T
+}
+
+func NewPrototypeRegistry[K comparable, T Cloner[T]]() *PrototypeRegistry[K, T] {
+	return &PrototypeRegistry[K, T]{protos: make(map[K]T)}
+}
+
+// Register adds a prototype. It fails when the key is taken or the prototype
+// is nil.
+func (r *PrototypeRegistry[K, T]) Register(key K, proto T) error {
+	if isNil(proto) {
+		return fmt.Errorf("%w: %v", ErrNilPrototype, key)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.protos[key]; ok {
+		return fmt.Errorf("%w: %v", ErrPrototypeExists, key)
+	}
+	r.protos[key] = proto.Clone()
+	return nil
+}
+
+// Update replaces an existing prototype. Clones made before keep the old
+// values.
+func (r *PrototypeRegistry[K, T]) Update(key K, proto T) error {
+	if isNil(proto) {
+		return fmt.Errorf("%w: %v", ErrNilPrototype, key)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.protos[key]; !ok {
+		return fmt.Errorf("%w: %v", ErrPrototypeNotFound, key)
+	}
+	r.protos[key] = proto.Clone()
+	return nil
+}
+
+// Remove deletes a prototype.
+func (r *PrototypeRegistry[K, T]) Remove(key K) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.protos[key]; !ok {
+		return fmt.Errorf("%w: %v", ErrPrototypeNotFound, key)
+	}
+	delete(r.protos, key)
+	return nil
+}
+
+// Clone returns a deep copy of the prototype.
+func (r *PrototypeRegistry[K, T]) Clone(key K) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	proto, ok := r.protos[key]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %v", ErrPrototypeNotFound, key)
+	}
+	return proto.Clone(), nil
+}
+
+// Keys returns the keys of the prototypes, in no particular order.
+func (r *PrototypeRegistry[K, T]) Keys() []K {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]K, 0, len(r.protos))
+	for k := range r.protos {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// LoadJSON replaces every prototype with the ones of a JSON object mapping
+// keys to prototypes. Keys must be strings, integers or implement
+// encoding.TextUnmarshaler. Nothing changes when decoding fails.
+func (r *PrototypeRegistry[K, T]) LoadJSON(rd io.Reader) error {
+	var protos map[K]T
+	dec := json.NewDecoder(rd)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&protos); err != nil {
+		return fmt.Errorf("loading prototypes: %w", err)
+	}
+	if protos == nil {
+		return errors.New("loading prototypes: null")
+	}
+	for k, p := range protos {
+		if isNil(p) {
+			return fmt.Errorf("loading prototypes: %w: %v", ErrNilPrototype, k)
+		}
+	}
+	r.mu.Lock()
+	r.protos = protos
+	r.mu.Unlock()
+	return nil
+}
+
+// isNil reports whether a prototype is a nil interface or pointer, which
+// would panic when cloned.
+func isNil[T any](proto T) bool {
+	v := reflect.ValueOf(proto)
+	return !v.IsValid() || v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// LoadFile is LoadJSON reading the named file.
+func (r *PrototypeRegistry[K, T]) LoadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.LoadJSON(f)
+}
+
+// SaveJSON writes the prototypes in the format read by LoadJSON.
+func (r *PrototypeRegistry[K, T]) SaveJSON(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.protos)
+}
@@ -0,0 +1,169 @@
+package prototype
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SKUGenerator hands out unique stock keeping units built from a pattern
+// such as "{code}-{year}-{seq:6}", which gives "WHT-2026-000123". The
+// placeholders are:
+//
+//	{code}   the code of the item, like WHT for a white shirt
+//	{year}   the current year
+//	{seq:N}  a sequence number padded to N digits ({seq} doesn't pad)
+//
+// Every combination of code and year has its own sequence, so two SKUs
+// are never equal. {code} must be followed by a literal separator and, unless
+// it starts the pattern, preceded by one. Otherwise "A" with sequence 11 and
+// "A1" with sequence 1 would both give "A11", and "{seq}{code}" would give
+// "11A" for both "A" with sequence 11 and "1A" with sequence 1. It is safe for
+// concurrent use.
+type SKUGenerator struct {
+	parts []skuPart
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+
+	mu   sync.Mutex
+	next map[string]uint64
+}
+
+type skuPart struct {
+	literal string
+	field   string // "code", "year" or "seq"
+	width   int
+}
+
+// DefaultSKUPattern is the pattern of the shirt SKUs.
+const DefaultSKUPattern = "{code}-{year}-{seq:6}"
+
+// NewSKUGenerator parses the pattern, which must contain {seq} once and a
+// literal between every {code} and the placeholders around it.
+func NewSKUGenerator(pattern string) (*SKUGenerator, error) {
+	g := &SKUGenerator{next: make(map[string]uint64)}
+	seqs := 0
+	for rest := pattern; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			g.parts = append(g.parts, skuPart{literal: rest})
+			break
+		}
+		if open > 0 {
+			g.parts = append(g.parts, skuPart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest, '}')
+		if end < open {
+			return nil, fmt.Errorf("sku pattern %q: unclosed {", pattern)
+		}
+		name, arg, hasArg := strings.Cut(rest[open+1:end], ":")
+		p := skuPart{field: name}
+		switch name {
+		case "code", "year":
+			if hasArg {
+				return nil, fmt.Errorf("sku pattern %q: {%s} takes no width", pattern, name)
+			}
+		case "seq":
+			seqs++
+			if hasArg {
+				w, err := strconv.Atoi(arg)
+				if err != nil || w <= 0 || w > 18 {
+					return nil, fmt.Errorf("sku pattern %q: bad width %q", pattern, arg)
+				}
+				p.width = w
+			}
+		default:
+			return nil, fmt.Errorf("sku pattern %q: unknown placeholder {%s}", pattern, name)
+		}
+		g.parts = append(g.parts, p)
+		rest = rest[end+1:]
+	}
+	if seqs != 1 {
+		return nil, errors.New("sku pattern must contain {seq} exactly once")
+	}
+	for i, p := range g.parts {
+		if p.field != "code" {
+			continue
+		}
+		if i+1 == len(g.parts) || g.parts[i+1].field != "" {
+			return nil, fmt.Errorf("sku pattern %q: {code} must be followed by a separator", pattern)
+		}
+		if i > 0 && g.parts[i-1].field != "" {
+			return nil, fmt.Errorf("sku pattern %q: {code} must be preceded by a separator", pattern)
+		}
+	}
+	return g, nil
+}
+
+// Next returns the next SKU for the code.
+func (g *SKUGenerator) Next(code string) string {
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	year := strconv.Itoa(now().Year())
+	key := g.key(code, year)
+	g.mu.Lock()
+	g.next[key]++
+	seq := g.next[key]
+	g.mu.Unlock()
+
+	n := strconv.FormatUint(seq, 10)
+	var sku strings.Builder
+	for _, p := range g.parts {
+		sku.WriteString(p.render(code, year, n))
+	}
+	return sku.String()
+}
+
+// Seed records that the SKUs of the code up to the sequence number last were
+// already handed out in the year, so that a generator restored after a
+// restart goes on from last+1. Seeding never moves a sequence backwards.
+func (g *SKUGenerator) Seed(code string, year int, last uint64) {
+	key := g.key(code, strconv.Itoa(year))
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next[key] = max(g.next[key], last)
+}
+
+// key returns the SKU without its sequence number, which identifies the
+// sequence of the code in the year.
+func (g *SKUGenerator) key(code, year string) string {
+	var key strings.Builder
+	for _, p := range g.parts {
+		key.WriteString(p.render(code, year, ""))
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+func (p skuPart) render(code, year, seq string) string {
+	switch p.field {
+	case "code":
+		return code
+	case "year":
+		return year
+	case "seq":
+		if seq == "" {
+			return ""
+		}
+		return strings.Repeat("0", max(0, p.width-len(seq))) + seq
+	}
+	return p.literal
+}
+
+// Code returns the three letter code of the color used in SKUs.
+func (c ShirtColor) Code() string {
+	switch c {
+	case White:
+		return "WHT"
+	case Black:
+		return "BLK"
+	case Blue:
+		return "BLU"
+	}
+	return fmt.Sprintf("C%02d", int(c))
+}
@@ -0,0 +1,11 @@
+package singleton
+
+// Reset forgets the value so the next Get runs the initializer again. It is
+// only available to tests, which must not call it while Get runs elsewhere.
+func (l *Lazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	l.value = zero
+	l.done.Store(false)
+}
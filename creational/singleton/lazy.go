@@ -0,0 +1,57 @@
+package singleton
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Lazy holds a value created on first use by an initializer. The initializer
+// runs once even when Get is called from many goroutines at the same time; if
+// it fails, the error is returned to that caller and the next Get tries
+// again.
+type Lazy[T any] struct {
+	init  func() (T, error)
+	mu    sync.Mutex
+	done  atomic.Bool
+	value T
+}
+
+// NewLazy returns a Lazy that creates its value with init.
+func NewLazy[T any](init func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{init: init}
+}
+
+// Get returns the value, creating it when needed. Once the value exists, Get
+// doesn't lock.
+func (l *Lazy[T]) Get() (T, error) {
+	if l.done.Load() {
+		return l.value, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.done.Load() {
+		return l.value, nil
+	}
+	v, err := l.init()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	l.value = v
+	l.done.Store(true)
+	return v, nil
+}
+
+// MustGet is like Get but panics if the initializer fails.
+func (l *Lazy[T]) MustGet() T {
+	v, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Initialized reports whether the value was already created.
+func (l *Lazy[T]) Initialized() bool {
+	return l.done.Load()
+}
@@ -0,0 +1,121 @@
+package singleton
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLazy_InitOnce(t *testing.T) {
+	var calls atomic.Int32
+	l := NewLazy(func() (*int, error) {
+		calls.Add(1)
+		return new(int), nil
+	})
+	const goroutines = 200
+	results := make([]*int, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := l.Get()
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}(i)
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("The initializer ran %d times", calls.Load())
+	}
+	for i, r := range results {
+		if r != results[0] {
+			t.Fatalf("Goroutine %d got a different instance", i)
+		}
+	}
+}
+
+func TestLazy_RetryAfterError(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	var calls atomic.Int32
+	l := NewLazy(func() (string, error) {
+		if calls.Add(1) <= 3 {
+			return "", errUnavailable
+		}
+		return "ready", nil
+	})
+	const goroutines = 50
+	var failures atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get()
+			switch {
+			case errors.Is(err, errUnavailable):
+				failures.Add(1)
+			case err != nil || v != "ready":
+				t.Errorf("Unexpected result %q, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if failures.Load() != 3 || calls.Load() != 4 {
+		t.Errorf("Expected 3 failures and 4 calls, got %d and %d", failures.Load(), calls.Load())
+	}
+	if !l.Initialized() {
+		t.Error("The value should be initialized after a successful retry")
+	}
+}
+
+func TestLazy_Reset(t *testing.T) {
+	n := 0
+	l := NewLazy(func() (int, error) {
+		n++
+		return n, nil
+	})
+	if v := l.MustGet(); v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+	l.Reset()
+	if l.Initialized() {
+		t.Error("Reset must forget the value")
+	}
+	if v := l.MustGet(); v != 2 {
+		t.Errorf("Expected the initializer to run again, got %d", v)
+	}
+}
+
+func TestLazy_MustGetPanics(t *testing.T) {
+	l := NewLazy(func() (int, error) { return 0, errors.New("boom") })
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet should panic when the initializer fails")
+		}
+	}()
+	l.MustGet()
+}
+
+func TestGetInstance_Concurrent(t *testing.T) {
+	instance.Reset()
+	defer instance.Reset()
+	const goroutines, adds = 100, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < adds; j++ {
+				GetInstance().AddOne()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := GetInstance().AddOne(); n != goroutines*adds+1 {
+		t.Errorf("Expected %d, got %d", goroutines*adds+1, n)
+	}
+}
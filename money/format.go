@@ -0,0 +1,199 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Locale holds how amounts are written in a region.
+type Locale struct {
+	Name    string
+	Decimal string
+	Group   string
+	// SymbolFirst puts the currency symbol before the number.
+	SymbolFirst bool
+	// SymbolSpace separates the symbol from the number with a space.
+	SymbolSpace bool
+}
+
+// Predefined locales.
+var (
+	EnUS = Locale{Name: "en-US", Decimal: ".", Group: ",", SymbolFirst: true}
+	EnGB = Locale{Name: "en-GB", Decimal: ".", Group: ",", SymbolFirst: true}
+	DeDE = Locale{Name: "de-DE", Decimal: ",", Group: ".", SymbolSpace: true}
+	FrFR = Locale{Name: "fr-FR", Decimal: ",", Group: " ", SymbolSpace: true}
+	PtPT = Locale{Name: "pt-PT", Decimal: ",", Group: " ", SymbolSpace: true}
+	PtBR = Locale{Name: "pt-BR", Decimal: ",", Group: ".", SymbolFirst: true, SymbolSpace: true}
+	JaJP = Locale{Name: "ja-JP", Decimal: ".", Group: ",", SymbolFirst: true}
+)
+
+// digits writes the absolute amount with the given separators, without
+// symbol nor sign.
+func (m Money) digits(decimal, group string) string {
+	a := m.amount
+	if a < 0 {
+		a = -a
+	}
+	s := strconv.FormatUint(uint64(a), 10)
+	d := m.currency.Digits
+	for len(s) <= d {
+		s = "0" + s
+	}
+	units, frac := s[:len(s)-d], s[len(s)-d:]
+	if group != "" {
+		var b strings.Builder
+		for i, r := range units {
+			if i > 0 && (len(units)-i)%3 == 0 {
+				b.WriteString(group)
+			}
+			b.WriteRune(r)
+		}
+		units = b.String()
+	}
+	if d == 0 {
+		return units
+	}
+	return units + decimal + frac
+}
+
+// Decimal returns the amount as a plain decimal number, like "-1234.50".
+func (m Money) Decimal() string {
+	sign := ""
+	if m.amount < 0 {
+		sign = "-"
+	}
+	return sign + m.digits(".", "")
+}
+
+// String returns the amount followed by the currency code, like
+// "1234.50 EUR".
+func (m Money) String() string {
+	if m.currency.Code == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.currency.Code
+}
+
+// Format writes the amount the way it is written in the locale, like
+// "$1,234.50" in en-US or "1.234,50 €" in de-DE.
+func (m Money) Format(l Locale) string {
+	sign := ""
+	if m.amount < 0 {
+		sign = "-"
+	}
+	num := m.digits(l.Decimal, l.Group)
+	sym := m.currency.Symbol
+	if sym == "" {
+		return sign + num
+	}
+	sep := ""
+	if l.SymbolSpace {
+		sep = " "
+	}
+	if l.SymbolFirst {
+		return sign + sym + sep + num
+	}
+	return sign + num + sep + sym
+}
+
+// Parse reads a plain decimal amount of the currency with the given code,
+// such as "1234.5" or "-0.99". More decimal places than the currency has are
+// rejected rather than rounded.
+func Parse(s, code string) (Money, error) {
+	c, err := CurrencyOf(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return parse(s, s, c)
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants in
+// code.
+func MustParse(s, code string) Money {
+	m, err := Parse(s, code)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// ParseLocale reads an amount written the way Format writes it in the
+// locale. The currency symbol or code is optional.
+func ParseLocale(s, code string, l Locale) (Money, error) {
+	c, err := CurrencyOf(code)
+	if err != nil {
+		return Money{}, err
+	}
+	t := strings.TrimSpace(s)
+	for _, affix := range []string{c.Symbol, c.Code} {
+		if affix == "" {
+			continue
+		}
+		t = strings.TrimSpace(strings.TrimPrefix(t, affix))
+		t = strings.TrimSpace(strings.TrimSuffix(t, affix))
+		if rest, ok := strings.CutPrefix(t, "-"+affix); ok {
+			t = "-" + strings.TrimSpace(rest)
+		}
+	}
+	if l.Group != "" {
+		t = strings.ReplaceAll(t, l.Group, "")
+	}
+	if l.Decimal != "." {
+		if strings.Contains(t, ".") {
+			return Money{}, fmt.Errorf("money: invalid amount %q for %s", s, l.Name)
+		}
+		t = strings.ReplaceAll(t, l.Decimal, ".")
+	}
+	return parse(s, t, c)
+}
+
+func parse(orig, s string, c Currency) (Money, error) {
+	invalid := fmt.Errorf("money: invalid amount %q", orig)
+	neg := strings.HasPrefix(s, "-")
+	units, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if units == "" || len(frac) > c.Digits || hasFrac && frac == "" {
+		return Money{}, invalid
+	}
+	for len(frac) < c.Digits {
+		frac += "0"
+	}
+	for _, r := range units + frac {
+		if r < '0' || r > '9' {
+			return Money{}, invalid
+		}
+	}
+	a, err := strconv.ParseInt(units+frac, 10, 64)
+	if err != nil {
+		return Money{}, invalid
+	}
+	if neg {
+		a = -a
+	}
+	return Money{amount: a, currency: c}, nil
+}
+
+// MarshalText encodes m as String does, so amounts read well in JSON.
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes an amount written by MarshalText, such as
+// "15.00 EUR". A bare "0" is the zero value without currency.
+func (m *Money) UnmarshalText(b []byte) error {
+	s := string(b)
+	if s == "0" {
+		*m = Money{}
+		return nil
+	}
+	amount, code, ok := strings.Cut(s, " ")
+	if !ok {
+		return fmt.Errorf("money: %q has no currency code", s)
+	}
+	v, err := Parse(amount, code)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
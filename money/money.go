@@ -0,0 +1,287 @@
+// Package money represents amounts of money exactly, as an integer number of
+// minor units (cents for EUR, yen for JPY) of an ISO 4217 currency. It
+// provides explicit rounding, allocation without losing minor units, and
+// locale aware formatting and parsing.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrUnknownCurrency is returned for currency codes not in the table.
+	ErrUnknownCurrency = errors.New("money: unknown currency")
+	// ErrCurrencyMismatch is returned when combining different currencies.
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	// ErrOverflow is returned when a result doesn't fit in an int64.
+	ErrOverflow = errors.New("money: overflow")
+)
+
+// Currency is an ISO 4217 currency.
+type Currency struct {
+	Code string
+	// Digits is the number of minor unit digits: 2 for EUR, 0 for JPY.
+	Digits int
+	Symbol string
+}
+
+var currencies = map[string]Currency{
+	"BRL": {"BRL", 2, "R$"},
+	"CAD": {"CAD", 2, "CA$"},
+	"CHF": {"CHF", 2, "CHF"},
+	"EUR": {"EUR", 2, "€"},
+	"GBP": {"GBP", 2, "£"},
+	"JPY": {"JPY", 0, "¥"},
+	"KWD": {"KWD", 3, "KD"},
+	"USD": {"USD", 2, "$"},
+}
+
+// CurrencyOf returns the currency with the given ISO 4217 code.
+func CurrencyOf(code string) (Currency, error) {
+	c, ok := currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// Money is an amount in minor units of a currency. The zero value is zero
+// with no currency, which adopts the currency of whatever it is added to, so
+// it can be used as the initial value of a sum.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns minor units of the currency with the given code, so
+// New(1050, "EUR") is 10.50 €.
+func New(minor int64, code string) (Money, error) {
+	c, err := CurrencyOf(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: minor, currency: c}, nil
+}
+
+// MustNew is like New but panics on an unknown currency. It is meant for
+// constants in code.
+func MustNew(minor int64, code string) Money {
+	m, err := New(minor, code)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.amount
+}
+
+// Currency returns the currency of m.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount.
+func (m Money) Sign() int {
+	switch {
+	case m.amount < 0:
+		return -1
+	case m.amount > 0:
+		return 1
+	}
+	return 0
+}
+
+// SameCurrency reports whether m and o can be combined.
+func (m Money) SameCurrency(o Money) bool {
+	return m.currency == o.currency || m.currency.Code == "" || o.currency.Code == ""
+}
+
+func (m Money) common(o Money) (Currency, error) {
+	if !m.SameCurrency(o) {
+		return Currency{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency.Code, o.currency.Code)
+	}
+	if m.currency.Code == "" {
+		return o.currency, nil
+	}
+	return m.currency, nil
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	c, err := m.common(o)
+	if err != nil {
+		return Money{}, err
+	}
+	s := m.amount + o.amount
+	if (s > m.amount) != (o.amount > 0) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: s, currency: c}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+// MustAdd is like Add but panics on a currency mismatch or an overflow.
+func (m Money) MustAdd(o Money) Money {
+	s, err := m.Add(o)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Cmp compares m and o, returning -1, 0 or 1.
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.common(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Times returns m multiplied by n.
+func (m Money) Times(n int64) Money {
+	return Money{amount: m.amount * n, currency: m.currency}
+}
+
+// Scale returns m * num / den, rounded to a minor unit with mode. A rate of
+// 23% is Scale(23, 100, HalfUp).
+func (m Money) Scale(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("money: division by zero")
+	}
+	if den < 0 {
+		num, den = -num, -den
+	}
+	n := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num))
+	q, err := divRound(n, big.NewInt(den), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: q, currency: m.currency}, nil
+}
+
+// RoundingMode tells how to round a result to a minor unit.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest, ties away from zero.
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest, ties to the even neighbour (banker's
+	// rounding).
+	HalfEven
+	// HalfDown rounds to the nearest, ties toward zero.
+	HalfDown
+	// Up rounds away from zero.
+	Up
+	// Down rounds toward zero (truncation).
+	Down
+	// Ceiling rounds toward positive infinity.
+	Ceiling
+	// Floor rounds toward negative infinity.
+	Floor
+)
+
+// divRound divides n by d > 0 and rounds the quotient with mode.
+func divRound(n, d *big.Int, mode RoundingMode) (int64, error) {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() != 0 {
+		sign := int64(n.Sign())
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		half := twice.Cmp(d)
+		away := false
+		switch mode {
+		case Up:
+			away = true
+		case Down:
+		case Ceiling:
+			away = sign > 0
+		case Floor:
+			away = sign < 0
+		case HalfUp:
+			away = half >= 0
+		case HalfDown:
+			away = half > 0
+		case HalfEven:
+			away = half > 0 || half == 0 && q.Bit(0) == 1
+		}
+		if away {
+			q.Add(q, big.NewInt(sign))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
+
+// Allocate splits m in shares proportional to ratios without losing minor
+// units: the remainder of the integer division is given, one minor unit at a
+// time, to the first shares.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, errors.New("money: negative ratio")
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, errors.New("money: ratios must not all be zero")
+	}
+	shares := make([]Money, len(ratios))
+	var allocated int64
+	for i, r := range ratios {
+		share, err := m.Scale(r, total, Down)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = share
+		allocated += share.amount
+	}
+	unit := int64(m.Sign())
+	for i := 0; allocated != m.amount; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].amount += unit
+		allocated += unit
+	}
+	return shares, nil
+}
+
+// Split divides m in n shares that differ by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("money: split in zero parts")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
@@ -0,0 +1,175 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	m, err := New(1050, "EUR")
+	if err != nil || m.Minor() != 1050 || m.Currency().Digits != 2 {
+		t.Errorf("Unexpected money %v, %v", m, err)
+	}
+	if _, err := New(1, "XXX"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	eur := MustNew(150, "EUR")
+	sum, err := Money{}.Add(eur)
+	if err != nil || sum != eur {
+		t.Errorf("The zero Money must adopt the currency, got %v, %v", sum, err)
+	}
+	sum, _ = sum.Add(MustNew(-200, "EUR"))
+	if sum.Minor() != -50 {
+		t.Errorf("Expected -0.50, got %v", sum)
+	}
+	if _, err := eur.Add(MustNew(1, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := MustNew(math.MaxInt64, "EUR").Add(MustNew(1, "EUR")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+	if c, err := eur.Cmp(MustNew(151, "EUR")); err != nil || c != -1 {
+		t.Errorf("Expected -1, got %d, %v", c, err)
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		amount int64
+		mode   RoundingMode
+		out    int64
+	}{
+		// 25 * 1/10 = 2.5
+		{25, HalfUp, 3}, {25, HalfEven, 2}, {25, HalfDown, 2},
+		{25, Up, 3}, {25, Down, 2}, {25, Ceiling, 3}, {25, Floor, 2},
+		{-25, HalfUp, -3}, {-25, HalfEven, -2}, {-25, HalfDown, -2},
+		{-25, Up, -3}, {-25, Down, -2}, {-25, Ceiling, -2}, {-25, Floor, -3},
+		// 35 * 1/10 = 3.5
+		{35, HalfEven, 4},
+		// 26 * 1/10 = 2.6
+		{26, HalfDown, 3}, {26, Down, 2},
+	}
+	for _, test := range tests {
+		m, err := MustNew(test.amount, "EUR").Scale(1, 10, test.mode)
+		if err != nil || m.Minor() != test.out {
+			t.Errorf("%d/10 with mode %d: expected %d, got %d, %v", test.amount, test.mode, test.out, m.Minor(), err)
+		}
+	}
+	vat, _ := MustNew(46799, "EUR").Scale(23, 100, HalfUp)
+	if vat.Minor() != 10764 {
+		t.Errorf("Expected 107.64, got %v", vat)
+	}
+	if _, err := MustNew(math.MaxInt64, "EUR").Scale(2, 1, HalfUp); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	shares, err := MustNew(100, "EUR").Allocate(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares[0].Minor() != 34 || shares[1].Minor() != 33 || shares[2].Minor() != 33 {
+		t.Errorf("Unexpected shares %v", shares)
+	}
+	shares, _ = MustNew(-1001, "EUR").Allocate(70, 0, 30)
+	if shares[0].Minor() != -701 || shares[1].Minor() != 0 || shares[2].Minor() != -300 {
+		t.Errorf("Unexpected shares %v", shares)
+	}
+	shares, _ = MustNew(5, "JPY").Split(3)
+	var total int64
+	for _, s := range shares {
+		total += s.Minor()
+	}
+	if total != 5 || len(shares) != 3 {
+		t.Errorf("Split lost minor units: %v", shares)
+	}
+	if _, err := MustNew(5, "EUR").Allocate(0, 0); err == nil {
+		t.Error("Allocating with zero ratios must fail")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	eur := MustNew(-123456789, "EUR")
+	tests := []struct {
+		m   Money
+		l   Locale
+		out string
+	}{
+		{MustNew(123450, "USD"), EnUS, "$1,234.50"},
+		{eur, DeDE, "-1.234.567,89 €"},
+		{eur, FrFR, "-1 234 567,89 €"},
+		{MustNew(5, "EUR"), PtPT, "0,05 €"},
+		{MustNew(123450, "BRL"), PtBR, "R$ 1.234,50"},
+		{MustNew(1235, "JPY"), JaJP, "¥1,235"},
+		{MustNew(1234567, "KWD"), EnGB, "KD1,234.567"},
+	}
+	for _, test := range tests {
+		if s := test.m.Format(test.l); s != test.out {
+			t.Errorf("Expected %q, got %q", test.out, s)
+		}
+		back, err := ParseLocale(test.out, test.m.Currency().Code, test.l)
+		if err != nil || back != test.m {
+			t.Errorf("ParseLocale(%q) = %v, %v; expected %v", test.out, back, err, test.m)
+		}
+	}
+	if s := eur.String(); s != "-1234567.89 EUR" {
+		t.Errorf("Unexpected String() %q", s)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		code string
+		out  int64
+	}{
+		{"12", "EUR", 1200}, {"12.5", "EUR", 1250}, {"-0.07", "EUR", -7},
+		{"1500", "JPY", 1500}, {"1.234", "KWD", 1234},
+	}
+	for _, test := range tests {
+		m, err := Parse(test.in, test.code)
+		if err != nil || m.Minor() != test.out {
+			t.Errorf("Parse(%q) = %v, %v; expected %d", test.in, m, err, test.out)
+		}
+	}
+	for _, bad := range []string{"", "1.234", "abc", ".5", "1.", "1,5", "--1"} {
+		if _, err := Parse(bad, "EUR"); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+	if _, err := ParseLocale("1.234,5 €", "EUR", FrFR); err == nil {
+		t.Error("A dot is not valid in a fr-FR amount")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type item struct {
+		Price Money `json:"price"`
+		Free  Money `json:"free"`
+	}
+	b, err := json.Marshal(item{Price: MustParse("15.5", "EUR")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"price":"15.50 EUR","free":"0"}` {
+		t.Errorf("Marshal() = %s", b)
+	}
+	var back item
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Price != MustParse("15.50", "EUR") || !back.Free.IsZero() {
+		t.Errorf("Unmarshal() = %+v", back)
+	}
+	for _, bad := range []string{`{"price":"15.50"}`, `{"price":"15.50 XXX"}`, `{"price":"15.505 EUR"}`} {
+		if err := json.Unmarshal([]byte(bad), &back); err == nil {
+			t.Errorf("%s accepted", bad)
+		}
+	}
+}
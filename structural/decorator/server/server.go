@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+
+	"github.com/antoniofmoliveira/patterns/creational/di"
 )
 
 type MyServer struct{}
@@ -47,6 +50,41 @@ func (m *MyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "Hello Decorator!")
 }
 
+// credentials are the user and password of the authenticated server.
+type credentials struct {
+	User, Password string
+}
+
+// newServer wires the server of the selection through a DI container: each
+// decorator is a constructor of its own, receiving the handler it wraps.
+func newServer(selection int, creds credentials, logs io.Writer) (http.Handler, error) {
+	c := di.New()
+	errs := []error{
+		di.Value(c, logs),
+		di.Value(c, creds),
+		c.Provide(di.Singleton, func() *MyServer { return new(MyServer) }),
+		c.Provide(di.Singleton, func(s *MyServer, creds credentials) *SimpleAuthMiddleware {
+			return &SimpleAuthMiddleware{Handler: s, User: creds.User, Password: creds.Password}
+		}),
+	}
+	switch selection {
+	case 2:
+		errs = append(errs, c.Provide(di.Singleton, func(s *MyServer, w io.Writer) http.Handler {
+			return &LoggerMiddleware{Handler: s, LogWriter: w}
+		}))
+	case 3:
+		errs = append(errs, c.Provide(di.Singleton, func(a *SimpleAuthMiddleware, w io.Writer) http.Handler {
+			return &LoggerMiddleware{Handler: a, LogWriter: w}
+		}))
+	default:
+		errs = append(errs, c.Provide(di.Singleton, func(s *MyServer) http.Handler { return s }))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return di.Resolve[http.Handler](c)
+}
+
 // func main() {
 // 	http.Handle("/", &LoggerMiddleware{
 // 		LogWriter: os.Stdout,
@@ -63,29 +101,14 @@ func main() {
 	var selection int
 	fmt.Fscanf(os.Stdin, "%d", &selection)
 
-	var mySuperServer http.Handler
-	switch selection {
-	case 1:
-		mySuperServer = new(MyServer)
-	case 2:
-		mySuperServer = &LoggerMiddleware{
-			Handler:   new(MyServer),
-			LogWriter: os.Stdout,
-		}
-	case 3:
-		var user, password string
+	var creds credentials
+	if selection == 3 {
 		fmt.Println("Enter user and password separated by a space")
-		fmt.Fscanf(os.Stdin, "%s %s", &user, &password)
-		mySuperServer = &LoggerMiddleware{
-			Handler: &SimpleAuthMiddleware{
-				Handler:  new(MyServer),
-				User:     user,
-				Password: password,
-			},
-			LogWriter: os.Stdout,
-		}
-	default:
-		mySuperServer = new(MyServer)
+		fmt.Fscanf(os.Stdin, "%s %s", &creds.User, &creds.Password)
+	}
+	mySuperServer, err := newServer(selection, creds, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	http.Handle("/", mySuperServer)
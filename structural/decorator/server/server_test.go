@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewServer(t *testing.T) {
+	creds := credentials{User: "admin", Password: "secret"}
+	for _, tc := range []struct {
+		selection int
+		auth      bool
+		body      string
+		logged    bool
+	}{
+		{1, false, "Hello Decorator!\n", false},
+		{2, false, "Hello Decorator!\n", true},
+		{3, false, "Error trying to retrieve data from Basic auth\n", true},
+		{3, true, "Hello Decorator!\n", true},
+	} {
+		var logs bytes.Buffer
+		server, err := newServer(tc.selection, creds, &logs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.auth {
+			req.SetBasicAuth(creds.User, creds.Password)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Body.String() != tc.body {
+			t.Errorf("Server %d: expected %q, got %q", tc.selection, tc.body, rec.Body.String())
+		}
+		if logged := strings.Contains(logs.String(), "Method: GET"); logged != tc.logged {
+			t.Errorf("Server %d: logged %t, expected %t", tc.selection, logged, tc.logged)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package flyweight
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownKey is returned by loaders for keys that don't exist, so that
+// callers get an error instead of some default flyweight.
+var ErrUnknownKey = errors.New("unknown key")
+
+// Policy chooses the entry evicted when a Cache is full.
+type Policy int
+
+const (
+	// LRU evicts the least recently used entry.
+	LRU Policy = iota
+	// LFU evicts the least frequently used entry, the least recently used
+	// among those used as rarely.
+	LFU
+)
+
+// CacheOptions bound a Cache. Zero values mean no limit.
+type CacheOptions[K comparable, V any] struct {
+	Policy     Policy
+	MaxEntries int
+	// MaxBytes bounds the sum of Size over the entries. Values bigger than
+	// MaxBytes are returned but not kept.
+	MaxBytes int64
+	Size     func(V) int64
+	// OnEvict is called, without the cache lock held, for every evicted
+	// entry.
+	OnEvict func(K, V)
+}
+
+// CacheStats are the counters of a Cache.
+type CacheStats struct {
+	Hits       int64
+	Misses     int64
+	LoadErrors int64
+	Evictions  int64
+	Entries    int
+	Bytes      int64
+}
+
+// Cache holds flyweights by key, loading the missing ones with its loader.
+// Concurrent Gets of a missing key share a single load. It is safe for
+// concurrent use.
+//
+// An evicted flyweight is loaded again the next time it is needed, so
+// callers still holding the old one then hold a different instance.
+type Cache[K comparable, V any] struct {
+	load func(K) (V, error)
+	opts CacheOptions[K, V]
+
+	mu       sync.Mutex
+	entries  map[K]*entry[K, V]
+	order    entryHeap[K, V]
+	inflight map[K]*call[V]
+	tick     uint64
+	stats    CacheStats
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	size  int64
+	freq  uint64
+	used  uint64
+	index int
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// NewCache returns a cache loading its values with load.
+func NewCache[K comparable, V any](load func(K) (V, error), opts CacheOptions[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		load:     load,
+		opts:     opts,
+		entries:  make(map[K]*entry[K, V]),
+		inflight: make(map[K]*call[V]),
+	}
+	c.order.policy = opts.Policy
+	return c
+}
+
+// Get returns the flyweight of key, loading it when missing. Load errors are
+// returned as is and not cached.
+func (c *Cache[K, V]) Get(key K) (V, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.stats.Hits++
+		c.touch(e)
+		c.mu.Unlock()
+		return e.value, nil
+	}
+	c.stats.Misses++
+	if cl, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-cl.done
+		return cl.value, cl.err
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	c.inflight[key] = cl
+	c.mu.Unlock()
+
+	func() {
+		// a panicking loader must not leave the waiters blocked
+		defer func() {
+			if r := recover(); r != nil {
+				cl.err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		cl.value, cl.err = c.load(key)
+	}()
+	if cl.err != nil {
+		cl.err = fmt.Errorf("loading %v: %w", key, cl.err)
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	var evicted []*entry[K, V]
+	if cl.err != nil {
+		c.stats.LoadErrors++
+	} else {
+		evicted = c.add(key, cl.value)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+	c.notify(evicted)
+	return cl.value, cl.err
+}
+
+// Remove drops key from the cache, without calling OnEvict.
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.drop(e)
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = len(c.entries)
+	return s
+}
+
+func (c *Cache[K, V]) touch(e *entry[K, V]) {
+	c.tick++
+	e.used = c.tick
+	e.freq++
+	heap.Fix(&c.order, e.index)
+}
+
+// add stores a loaded value and returns the entries evicted to make room.
+func (c *Cache[K, V]) add(key K, v V) []*entry[K, V] {
+	var size int64
+	if c.opts.Size != nil {
+		size = c.opts.Size(v)
+	}
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return nil
+	}
+	c.tick++
+	e := &entry[K, V]{key: key, value: v, size: size, freq: 1, used: c.tick}
+	var evicted []*entry[K, V]
+	for c.order.Len() > 0 && (c.opts.MaxEntries > 0 && len(c.entries) >= c.opts.MaxEntries ||
+		c.opts.MaxBytes > 0 && c.stats.Bytes+size > c.opts.MaxBytes) {
+		victim := c.order.entries[0]
+		c.drop(victim)
+		c.stats.Evictions++
+		evicted = append(evicted, victim)
+	}
+	c.entries[key] = e
+	heap.Push(&c.order, e)
+	c.stats.Bytes += size
+	return evicted
+}
+
+func (c *Cache[K, V]) drop(e *entry[K, V]) {
+	heap.Remove(&c.order, e.index)
+	delete(c.entries, e.key)
+	c.stats.Bytes -= e.size
+}
+
+func (c *Cache[K, V]) notify(evicted []*entry[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.opts.OnEvict(e.key, e.value)
+	}
+}
+
+// entryHeap orders the entries by eviction priority, the next victim first.
+type entryHeap[K comparable, V any] struct {
+	policy  Policy
+	entries []*entry[K, V]
+}
+
+func (h entryHeap[K, V]) Len() int { return len(h.entries) }
+
+func (h entryHeap[K, V]) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	if h.policy == LFU && a.freq != b.freq {
+		return a.freq < b.freq
+	}
+	return a.used < b.used
+}
+
+func (h entryHeap[K, V]) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *entryHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.index = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *entryHeap[K, V]) Pop() any {
+	old := h.entries
+	e := old[len(old)-1]
+	h.entries = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,135 @@
+package flyweight
+
+import (
+	"slices"
+
+	"github.com/antoniofmoliveira/patterns/creational/prototype"
+)
+
+// Clone returns a deep copy of the team, copying every shield, photo and
+// match eagerly.
+func (t *Team) Clone() *Team {
+	c := *t
+	c.Shield = slices.Clone(t.Shield)
+	c.Players = slices.Clone(t.Players)
+	for i := range c.Players {
+		c.Players[i].Photo = slices.Clone(c.Players[i].Photo)
+	}
+	c.HistoricalData = slices.Clone(t.HistoricalData)
+	for i := range c.HistoricalData {
+		c.HistoricalData[i].LeagueResults = slices.Clone(c.HistoricalData[i].LeagueResults)
+	}
+	return &c
+}
+
+// COWTeam is a copy-on-write Team: Clone shares the shield, players and
+// history with the original, and the accessors copy only the pages or the
+// photo they modify. Player photos are treated as immutable, so the photo
+// returned by Player must not be modified; use SetPlayerPhoto or
+// WritePlayerPhoto instead.
+type COWTeam struct {
+	ID      uint64
+	Name    string
+	shield  prototype.Slice[byte]
+	players prototype.Slice[Player]
+	history prototype.Slice[HistoricalData]
+}
+
+// NewCOWTeam copies t into a COWTeam, which then owns its data.
+func NewCOWTeam(t *Team) *COWTeam {
+	c := t.Clone()
+	return &COWTeam{
+		ID:      t.ID,
+		Name:    t.Name,
+		shield:  prototype.NewSlice(c.Shield, 0),
+		players: prototype.NewSlice(c.Players, 0),
+		history: prototype.NewSlice(c.HistoricalData, 0),
+	}
+}
+
+// Clone returns a team sharing the data of t until either is modified.
+func (t *COWTeam) Clone() *COWTeam {
+	return &COWTeam{
+		ID:      t.ID,
+		Name:    t.Name,
+		shield:  t.shield.Clone(),
+		players: t.players.Clone(),
+		history: t.history.Clone(),
+	}
+}
+
+// Team returns an independent deep copy of the team as a plain Team.
+func (t *COWTeam) Team() *Team {
+	players := t.players.Copy()
+	for i := range players {
+		players[i].Photo = slices.Clone(players[i].Photo)
+	}
+	history := t.history.Copy()
+	for i := range history {
+		history[i].LeagueResults = slices.Clone(history[i].LeagueResults)
+	}
+	return &Team{ID: t.ID, Name: t.Name, Shield: t.shield.Copy(), Players: players, HistoricalData: history}
+}
+
+// Shield returns a copy of the shield image.
+func (t *COWTeam) Shield() []byte {
+	return t.shield.Copy()
+}
+
+// WriteShield overwrites part of the shield image from offset off.
+func (t *COWTeam) WriteShield(off int, p []byte) {
+	t.shield.CopyIn(off, p)
+}
+
+func (t *COWTeam) NumPlayers() int {
+	return t.players.Len()
+}
+
+// Player returns the player at index i. Its photo is shared and must not be
+// modified.
+func (t *COWTeam) Player(i int) Player {
+	return t.players.At(i)
+}
+
+// SetPlayer replaces the player at index i, keeping a copy of its photo.
+func (t *COWTeam) SetPlayer(i int, p Player) {
+	p.Photo = slices.Clone(p.Photo)
+	t.players.Set(i, p)
+}
+
+// SetPlayerPhoto replaces the photo of the player at index i with a copy of
+// photo.
+func (t *COWTeam) SetPlayerPhoto(i int, photo []byte) {
+	p := t.players.At(i)
+	p.Photo = slices.Clone(photo)
+	t.players.Set(i, p)
+}
+
+// WritePlayerPhoto overwrites part of the photo of the player at index i
+// from offset off; bytes past its end are dropped. Only that photo is
+// copied.
+func (t *COWTeam) WritePlayerPhoto(i, off int, b []byte) {
+	p := t.players.At(i)
+	photo := slices.Clone(p.Photo)
+	copy(photo[off:], b)
+	p.Photo = photo
+	t.players.Set(i, p)
+}
+
+// History returns the historical data of the season at index i. Its league
+// results are shared and must not be modified.
+func (t *COWTeam) History(i int) HistoricalData {
+	return t.history.At(i)
+}
+
+// AddResult appends a match to the results of the season at index i.
+func (t *COWTeam) AddResult(i int, m Match) {
+	h := t.history.At(i)
+	h.LeagueResults = append(slices.Clip(h.LeagueResults), m)
+	t.history.Set(i, h)
+}
+
+// SharedPages returns how many pages of data t shares with other teams.
+func (t *COWTeam) SharedPages() int {
+	return t.shield.SharedPages() + t.players.SharedPages() + t.history.SharedPages()
+}
@@ -1,6 +1,10 @@
 package flyweight
 
-import "time"
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
 
 type Match struct {
 	Date          time.Time
@@ -34,40 +38,78 @@ const (
 	TEAM_B = "B"
 )
 
-type teamFlyweightFactory struct {
-	createdTeams map[string]*Team
+// TeamFactory hands out shared Team flyweights by team id.
+type TeamFactory struct {
+	teams *Cache[string, *Team]
 }
 
-func NewTeamFactory() teamFlyweightFactory {
-	return teamFlyweightFactory{
-		createdTeams: make(map[string]*Team),
-	}
+// NewTeamFactory returns a factory keeping every team loaded.
+func NewTeamFactory() *TeamFactory {
+	return NewBoundedTeamFactory(CacheOptions[string, *Team]{})
 }
 
-func (t *teamFlyweightFactory) GetTeam(teamID string) *Team {
-	if t.createdTeams[teamID] != nil {
-		return t.createdTeams[teamID]
+// NewBoundedTeamFactory returns a factory whose cache is bounded by opts.
+// The size of a team defaults to TeamSize.
+func NewBoundedTeamFactory(opts CacheOptions[string, *Team]) *TeamFactory {
+	return NewTeamFactoryWith(loadTeam, opts)
+}
+
+// NewTeamFactoryWith returns a factory loading its teams with load, which
+// should wrap ErrUnknownKey for unknown ids.
+func NewTeamFactoryWith(load func(teamID string) (*Team, error), opts CacheOptions[string, *Team]) *TeamFactory {
+	if opts.Size == nil {
+		opts.Size = TeamSize
 	}
-	team := getTeamFactory(teamID)
-	t.createdTeams[teamID] = &team
-	return t.createdTeams[teamID]
+	return &TeamFactory{teams: NewCache(load, opts)}
+}
+
+// GetTeam returns the team with the given id, or nil when there is no such
+// team.
+func (t *TeamFactory) GetTeam(teamID string) *Team {
+	team, _ := t.Team(teamID)
+	return team
+}
+
+// Team returns the team with the given id. Unknown ids give an error
+// wrapping ErrUnknownKey.
+func (t *TeamFactory) Team(teamID string) (*Team, error) {
+	return t.teams.Get(teamID)
+}
+
+func (t *TeamFactory) GetNumberOfObjects() int {
+	return t.teams.Len()
+}
+
+func (t *TeamFactory) Stats() CacheStats {
+	return t.teams.Stats()
 }
 
-func (t *teamFlyweightFactory) GetNumberOfObjects() int {
-	return len(t.createdTeams)
+// TeamSize approximates the memory used by a team: its images, players and
+// matches.
+func TeamSize(t *Team) int64 {
+	size := int64(len(t.Name) + len(t.Shield))
+	for _, p := range t.Players {
+		size += int64(len(p.Name) + len(p.Surname) + len(p.Photo))
+	}
+	for _, h := range t.HistoricalData {
+		size += int64(len(h.LeagueResults)) * int64(unsafe.Sizeof(Match{}))
+	}
+	return size
 }
 
-func getTeamFactory(team string) Team {
+func loadTeam(team string) (*Team, error) {
 	switch team {
+	case TEAM_A:
+		return &Team{
+			ID:   1,
+			Name: TEAM_A,
+		}, nil
 	case TEAM_B:
-		return Team{
+		return &Team{
 			ID:   2,
 			Name: TEAM_B,
-		}
+		}, nil
 	default:
-		return Team{
-			ID:   1,
-			Name: TEAM_A,
-		}
+		return nil, fmt.Errorf("team %q: %w", team, ErrUnknownKey)
 	}
 }
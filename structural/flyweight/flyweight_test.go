@@ -1,8 +1,16 @@
 package flyweight
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"unsafe"
 )
 
 func TestTeamFlyweightFactory_GetTeam(t *testing.T) {
@@ -41,3 +49,324 @@ func Test_HighVolume(t *testing.T) {
 			&teams[i])
 	}
 }
+
+func bigTeam() *Team {
+	team := &Team{ID: 1, Name: TEAM_A, Shield: make([]byte, 64<<10)}
+	for i := range 25 {
+		team.Players = append(team.Players, Player{Name: fmt.Sprint("player", i), Photo: make([]byte, 32<<10)})
+	}
+	team.HistoricalData = []HistoricalData{{Year: 24, LeagueResults: make([]Match, 38)}}
+	return team
+}
+
+func TestCOWTeam(t *testing.T) {
+	proto := NewCOWTeam(bigTeam())
+	clone := proto.Clone()
+	clone.WriteShield(100, []byte{1, 2, 3})
+	clone.WritePlayerPhoto(3, 10, []byte{9})
+	clone.SetPlayer(4, Player{Name: "new", Photo: []byte{1}})
+	clone.AddResult(0, Match{LocalScore: 3})
+
+	if proto.Shield()[100] != 0 || proto.Player(3).Photo[10] != 0 || proto.Player(4).Name != "player4" || len(proto.History(0).LeagueResults) != 38 {
+		t.Error("modifying the clone changed the prototype")
+	}
+	if clone.Shield()[101] != 2 || clone.Player(3).Photo[10] != 9 || clone.Player(4).Name != "new" || len(clone.History(0).LeagueResults) != 39 {
+		t.Error("clone not modified")
+	}
+	// the other 15 shield pages are still shared
+	if got := clone.SharedPages(); got != 15 {
+		t.Errorf("clone shares %d pages, want 15", got)
+	}
+
+	plain := clone.Team()
+	plain.Players[3].Photo[10] = 0
+	if clone.Player(3).Photo[10] != 9 {
+		t.Error("Team() shares data with the clone")
+	}
+	if !reflect.DeepEqual(NewCOWTeam(plain).Team(), plain) {
+		t.Error("round trip through COWTeam changed the team")
+	}
+}
+
+const numClones = 2000
+
+func BenchmarkCloneEager(b *testing.B) {
+	proto := bigTeam()
+	b.ReportAllocs()
+	for range b.N {
+		clones := make([]*Team, numClones)
+		for i := range clones {
+			clones[i] = proto.Clone()
+			clones[i].Players[i%25].Photo[0] = 1
+		}
+	}
+}
+
+func BenchmarkCloneCOW(b *testing.B) {
+	proto := NewCOWTeam(bigTeam())
+	b.ReportAllocs()
+	for range b.N {
+		clones := make([]*COWTeam, numClones)
+		for i := range clones {
+			clones[i] = proto.Clone()
+			clones[i].WritePlayerPhoto(i%25, 0, []byte{1})
+		}
+	}
+}
+
+func TestUnknownTeam(t *testing.T) {
+	factory := NewTeamFactory()
+	if _, err := factory.Team("Z"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Team(Z) = %v, want ErrUnknownKey", err)
+	}
+	if factory.GetTeam("Z") != nil || factory.GetNumberOfObjects() != 0 {
+		t.Error("unknown team cached")
+	}
+	if s := factory.Stats(); s.LoadErrors != 2 || s.Misses != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func keysOf[K comparable, V any](c *Cache[K, V], keys ...K) []K {
+	var in []K
+	for _, k := range keys {
+		c.mu.Lock()
+		_, ok := c.entries[k]
+		c.mu.Unlock()
+		if ok {
+			in = append(in, k)
+		}
+	}
+	return in
+}
+
+func identity(k int) (int, error) { return k, nil }
+
+func TestCacheLRU(t *testing.T) {
+	var evicted []int
+	c := NewCache(identity, CacheOptions[int, int]{MaxEntries: 3, OnEvict: func(k, _ int) { evicted = append(evicted, k) }})
+	for _, k := range []int{1, 2, 3, 1, 4, 5} {
+		c.Get(k)
+	}
+	if got := keysOf(c, 1, 2, 3, 4, 5); !reflect.DeepEqual(got, []int{1, 4, 5}) {
+		t.Errorf("kept %v", got)
+	}
+	if !reflect.DeepEqual(evicted, []int{2, 3}) {
+		t.Errorf("evicted %v", evicted)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 5 || s.Evictions != 2 || s.Entries != 3 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestCacheLFU(t *testing.T) {
+	c := NewCache(identity, CacheOptions[int, int]{Policy: LFU, MaxEntries: 3})
+	for _, k := range []int{1, 1, 1, 2, 2, 3, 4, 3, 5} {
+		c.Get(k)
+	}
+	// 3 was evicted for 4 (used once, less recently than 2 twice), then 4
+	// for 5
+	if got := keysOf(c, 1, 2, 3, 4, 5); !reflect.DeepEqual(got, []int{1, 2, 5}) {
+		t.Errorf("kept %v", got)
+	}
+}
+
+func TestCacheBytes(t *testing.T) {
+	c := NewCache(func(k int) ([]byte, error) { return make([]byte, k), nil },
+		CacheOptions[int, []byte]{MaxBytes: 100, Size: func(b []byte) int64 { return int64(len(b)) }})
+	c.Get(40)
+	c.Get(50)
+	c.Get(30) // evicts 40
+	if got := keysOf(c, 40, 50, 30); !reflect.DeepEqual(got, []int{50, 30}) || c.Stats().Bytes != 80 {
+		t.Errorf("kept %v, %d bytes", got, c.Stats().Bytes)
+	}
+	if b, err := c.Get(150); err != nil || len(b) != 150 {
+		t.Errorf("oversized Get() = %d bytes, %v", len(b), err)
+	}
+	if c.Len() != 2 || c.Stats().Bytes != 80 {
+		t.Error("oversized value cached")
+	}
+	c.Remove(50)
+	if c.Stats().Bytes != 30 {
+		t.Errorf("bytes after Remove() = %d", c.Stats().Bytes)
+	}
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	c := NewCache(func(k string) (*Team, error) {
+		loads.Add(1)
+		<-release
+		return loadTeam(k)
+	}, CacheOptions[string, *Team]{})
+	var wg sync.WaitGroup
+	teams := make([]*Team, 50)
+	for i := range teams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			teams[i], _ = c.Get(TEAM_B)
+		}()
+	}
+	for c.Stats().Misses < int64(len(teams)) {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+	if loads.Load() != 1 {
+		t.Errorf("%d loads, want 1", loads.Load())
+	}
+	for _, team := range teams {
+		if team != teams[0] || team.Name != TEAM_B {
+			t.Fatal("waiters got different teams")
+		}
+	}
+}
+
+func TestCachePanic(t *testing.T) {
+	c := NewCache(func(int) (int, error) { panic("boom") }, CacheOptions[int, int]{})
+	if _, err := c.Get(1); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Get() = %v", err)
+	}
+	if _, err := c.Get(1); err == nil {
+		t.Error("second Get() didn't load again")
+	}
+}
+
+func TestBoundedTeamFactory(t *testing.T) {
+	factory := NewBoundedTeamFactory(CacheOptions[string, *Team]{MaxEntries: 1})
+	a := factory.GetTeam(TEAM_A)
+	factory.GetTeam(TEAM_B)
+	if factory.GetNumberOfObjects() != 1 {
+		t.Errorf("%d teams cached", factory.GetNumberOfObjects())
+	}
+	if factory.GetTeam(TEAM_A) == a {
+		t.Error("evicted team was not loaded again")
+	}
+	// name, shield, 25 photos, the names player0 to player24 and the matches
+	if got := TeamSize(bigTeam()); got != 1+64<<10+25*(32<<10)+(10*7+15*8)+38*int64(unsafe.Sizeof(Match{})) {
+		t.Errorf("TeamSize() = %d", got)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+	a := in.String(strings.Repeat("ab", 3))
+	b := in.String(strings.Repeat("ab", 3))
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("equal strings not shared")
+	}
+	in.String(a)
+	in.String("other")
+	if s := in.Stats(); s.Unique != 2 || s.Shared != 1 || s.SavedBytes != 6 {
+		t.Errorf("stats = %+v", s)
+	}
+	line := "name=" + strings.Repeat("x", 1<<10)
+	if name := in.String(line[:4]); unsafe.StringData(name) == unsafe.StringData(line) {
+		t.Error("interned substring pins its line")
+	}
+}
+
+func TestBlobStore(t *testing.T) {
+	bs := NewBlobStore()
+	orig := make([]byte, 10, 20)
+	a := bs.Intern(orig)
+	b := bs.Intern(make([]byte, 10))
+	if &a[0] != &b[0] || cap(a) != 10 {
+		t.Error("equal blobs not shared")
+	}
+	orig[0] = 9
+	if a[0] != 0 {
+		t.Error("the stored blob aliases the caller slice")
+	}
+	c := bs.Intern([]byte{1})
+	if c[0] != 1 || bs.Intern(nil) != nil {
+		t.Error("unexpected blob")
+	}
+	if s := bs.Stats(); s.Unique != 2 || s.Shared != 1 || s.SavedBytes != 10 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+// syntheticLeague builds teams the way a decoder would: every string and
+// image is its own allocation even when equal to others. Players share a
+// few surnames and most have the placeholder photo.
+func syntheticLeague(teams, players, matches int) []*Team {
+	surnames := []string{"Silva", "Santos", "Ferreira", "Pereira", "Oliveira", "Costa"}
+	league := make([]*Team, teams)
+	for i := range league {
+		team := &Team{ID: uint64(i), Name: fmt.Sprint("team", i%10), Shield: bytes.Repeat([]byte{byte(i % 4)}, 8<<10)}
+		for p := range players {
+			photo := bytes.Repeat([]byte{0}, 4<<10)
+			if p%10 == 0 {
+				n := i*players + p + 1
+				photo[0], photo[1] = byte(n), byte(n>>8)
+			}
+			team.Players = append(team.Players, Player{
+				Name:    fmt.Sprint("player", p%20),
+				Surname: strings.Clone(surnames[(i+p)%len(surnames)]),
+				Photo:   photo,
+			})
+		}
+		results := make([]Match, matches/teams)
+		for m := range results {
+			results[m] = Match{LocalID: uint64(i), VisitorID: uint64((i + m + 1) % teams), LocalScore: byte(m % 4)}
+		}
+		team.HistoricalData = []HistoricalData{{Year: 25, LeagueResults: results}}
+		league[i] = team
+	}
+	return league
+}
+
+func TestDeduper(t *testing.T) {
+	league := syntheticLeague(20, 25, 380)
+	before := league[3].Players[1]
+	d := NewDeduper()
+	for _, team := range league {
+		d.Team(team)
+	}
+	after := league[3].Players[1]
+	if after.Name != before.Name || after.Surname != before.Surname || !bytes.Equal(after.Photo, before.Photo) {
+		t.Error("dedup changed a player")
+	}
+	p1, p2 := league[0].Players[1], league[5].Players[1]
+	if unsafe.StringData(p1.Name) != unsafe.StringData(p2.Name) || &p1.Photo[0] != &p2.Photo[0] {
+		t.Error("duplicates not shared")
+	}
+	if &league[0].Shield[0] != &league[4].Shield[0] {
+		t.Error("shields not shared")
+	}
+	// 20 teams x 25 players, of which 3 per team have their own photo, and
+	// one placeholder
+	if s := d.Blobs.Stats(); s.Unique != 20*3+1+4 {
+		t.Errorf("blob stats = %+v", s)
+	}
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+func benchmarkLeague(b *testing.B, dedup bool) {
+	for range b.N {
+		base := heapInUse()
+		league := syntheticLeague(200, 25, 5000)
+		if dedup {
+			d := NewDeduper()
+			for _, team := range league {
+				d.Team(team)
+			}
+		}
+		b.ReportMetric(float64(int64(heapInUse())-int64(base))/(1<<20), "heap-MiB")
+		runtime.KeepAlive(league)
+	}
+}
+
+func BenchmarkLeagueHeap(b *testing.B) {
+	b.Run("plain", func(b *testing.B) { benchmarkLeague(b, false) })
+	b.Run("dedup", func(b *testing.B) { benchmarkLeague(b, true) })
+}
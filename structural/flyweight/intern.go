@@ -0,0 +1,126 @@
+package flyweight
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// InternStats tell how much sharing saved.
+type InternStats struct {
+	// Unique is the number of distinct values stored.
+	Unique int
+	// Shared counts the values replaced by an equal one already stored, and
+	// SavedBytes the bytes they no longer hold.
+	Shared     int64
+	SavedBytes int64
+}
+
+// Interner is a table of strings: equal strings passed to String come back
+// as a single instance, so duplicates can be freed. It is safe for
+// concurrent use.
+type Interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+	stats   InternStats
+}
+
+func NewInterner() *Interner {
+	return &Interner{strings: make(map[string]string)}
+}
+
+// String returns the interned instance equal to s. The first time, it keeps
+// a copy of s, so that a substring doesn't pin the larger string it was cut
+// from.
+func (in *Interner) String(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if shared, ok := in.strings[s]; ok {
+		if len(s) > 0 && unsafe.StringData(shared) != unsafe.StringData(s) {
+			in.stats.Shared++
+			in.stats.SavedBytes += int64(len(s))
+		}
+		return shared
+	}
+	s = strings.Clone(s)
+	in.strings[s] = s
+	in.stats.Unique++
+	return s
+}
+
+func (in *Interner) Stats() InternStats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.stats
+}
+
+// BlobStore deduplicates byte slices by the SHA-256 of their content, such
+// as the photos of players that share the same placeholder image. The
+// slices it returns are shared and must not be modified. It is safe for
+// concurrent use.
+type BlobStore struct {
+	mu    sync.Mutex
+	blobs map[[sha256.Size]byte][]byte
+	stats InternStats
+}
+
+func NewBlobStore() *BlobStore {
+	return &BlobStore{blobs: make(map[[sha256.Size]byte][]byte)}
+}
+
+// Intern returns the stored slice with the content of b, storing a copy of
+// b the first time so the caller can go on using b. The capacity of the
+// result is its length, so appending to it never writes to the shared array.
+// nil stays nil.
+func (bs *BlobStore) Intern(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	sum := sha256.Sum256(b)
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if shared, ok := bs.blobs[sum]; ok {
+		if len(b) > 0 && &shared[0] != &b[0] {
+			bs.stats.Shared++
+			bs.stats.SavedBytes += int64(len(b))
+		}
+		return shared
+	}
+	b = slices.Clip(bytes.Clone(b))
+	bs.blobs[sum] = b
+	bs.stats.Unique++
+	return b
+}
+
+func (bs *BlobStore) Stats() InternStats {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.stats
+}
+
+// Deduper replaces the strings and images of teams with shared instances.
+type Deduper struct {
+	Strings *Interner
+	Blobs   *BlobStore
+}
+
+func NewDeduper() *Deduper {
+	return &Deduper{Strings: NewInterner(), Blobs: NewBlobStore()}
+}
+
+// Team walks the team and its players, interning names and surnames and
+// sharing the shield and photos. Afterwards the images of the team must be
+// treated as read only.
+func (d *Deduper) Team(t *Team) {
+	t.Name = d.Strings.String(t.Name)
+	t.Shield = d.Blobs.Intern(t.Shield)
+	for i := range t.Players {
+		p := &t.Players[i]
+		p.Name = d.Strings.String(p.Name)
+		p.Surname = d.Strings.String(p.Surname)
+		p.Photo = d.Blobs.Intern(p.Photo)
+	}
+}
@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/antoniofmoliveira/patterns/structural/flyweight"
+)
+
+// csvHeader is the header expected by ImportCSV.
+var csvHeader = []string{"date", "local", "visitor", "local_score", "visitor_score", "local_shots", "visitor_shots"}
+
+// ImportCSV adds the matches of a CSV file with the header
+//
+//	date,local,visitor,local_score,visitor_score,local_shots,visitor_shots
+//
+// where dates are written 2006-01-02 and teams by name. The teams of the
+// file join the league along with its matches: when a line is invalid,
+// neither a match nor a team is added.
+func (l *League) ImportCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("importing matches: %w", err)
+	}
+	if !slices.Equal(header, csvHeader) {
+		return fmt.Errorf("importing matches: header must be %v", csvHeader)
+	}
+	cr.FieldsPerRecord = len(csvHeader)
+	var matches []flyweight.Match
+	teams := make(map[uint64]*flyweight.Team)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("importing matches: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		m, err := l.parseMatch(rec, teams)
+		if err != nil {
+			return fmt.Errorf("importing matches: line %d: %w", line, err)
+		}
+		matches = append(matches, m)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, t := range teams {
+		if err := l.conflict(t); err != nil {
+			return fmt.Errorf("importing matches: %w", err)
+		}
+	}
+	for _, t := range teams {
+		l.join(t)
+	}
+	l.matches = append(l.matches, matches...)
+	return nil
+}
+
+// parseMatch reads a line of ImportCSV. Its teams are looked up in the
+// factory and collected in teams, without joining the league yet.
+func (l *League) parseMatch(rec []string, teams map[uint64]*flyweight.Team) (flyweight.Match, error) {
+	var m flyweight.Match
+	date, err := time.Parse(time.DateOnly, rec[0])
+	if err != nil {
+		return m, fmt.Errorf("date %q: %w", rec[0], err)
+	}
+	m.Date = date
+	var found [2]*flyweight.Team
+	for i, name := range rec[1:3] {
+		t, err := l.teams.Team(name)
+		if err != nil {
+			return m, err
+		}
+		if other, ok := teams[t.ID]; ok && other.Name != t.Name {
+			return m, sameID(other, t)
+		}
+		teams[t.ID], found[i] = t, t
+	}
+	local, visitor := found[0], found[1]
+	if local.ID == visitor.ID {
+		return m, fmt.Errorf("%s can't play itself", local.Name)
+	}
+	m.LocalID, m.VisitorID = local.ID, visitor.ID
+	nums := make([]uint64, 4)
+	for i := range nums {
+		bits := 8
+		if i >= 2 {
+			bits = 16
+		}
+		nums[i], err = strconv.ParseUint(rec[3+i], 10, bits)
+		if err != nil {
+			return m, fmt.Errorf("%s %q is not a valid number", csvHeader[3+i], rec[3+i])
+		}
+	}
+	m.LocalScore, m.VisitorScore = byte(nums[0]), byte(nums[1])
+	m.LocalShoots, m.VisitorShoots = uint16(nums[2]), uint16(nums[3])
+	if int(m.LocalShoots) < int(m.LocalScore) || int(m.VisitorShoots) < int(m.VisitorScore) {
+		return m, fmt.Errorf("more goals than shots")
+	}
+	return m, nil
+}
+
+// WriteText writes the standings as an aligned table.
+func WriteText(w io.Writer, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "#\tTeam\tP\tW\tD\tL\tGF\tGA\tGD\tPts\tConv\tForm\t")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%+d\t%d\t%.0f%%\t%s\t\n",
+			r.Position, r.Team.Name, r.Played, r.Won, r.Drawn, r.Lost,
+			r.GoalsFor, r.GoalsAgainst, r.GoalDifference(), r.Points, r.Conversion()*100, r.Form)
+	}
+	return tw.Flush()
+}
+
+type jsonRow struct {
+	Position       int     `json:"position"`
+	Team           string  `json:"team"`
+	Played         int     `json:"played"`
+	Won            int     `json:"won"`
+	Drawn          int     `json:"drawn"`
+	Lost           int     `json:"lost"`
+	GoalsFor       int     `json:"goals_for"`
+	GoalsAgainst   int     `json:"goals_against"`
+	GoalDifference int     `json:"goal_difference"`
+	Points         int     `json:"points"`
+	Shots          int     `json:"shots"`
+	Conversion     float64 `json:"conversion"`
+	Form           string  `json:"form"`
+}
+
+// WriteJSON writes the standings as an indented JSON array, teams by name.
+func WriteJSON(w io.Writer, rows []Row) error {
+	out := make([]jsonRow, len(rows))
+	for i, r := range rows {
+		out[i] = jsonRow{
+			Position: r.Position, Team: r.Team.Name, Played: r.Played,
+			Won: r.Won, Drawn: r.Drawn, Lost: r.Lost,
+			GoalsFor: r.GoalsFor, GoalsAgainst: r.GoalsAgainst, GoalDifference: r.GoalDifference(),
+			Points: r.Points, Shots: r.Shots, Conversion: r.Conversion(), Form: r.Form,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,363 @@
+// Package stats computes league tables and team records from the matches
+// of the flyweight package: standings, goal difference, head-to-head
+// records, streaks and shot conversion.
+package stats
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/antoniofmoliveira/patterns/structural/flyweight"
+)
+
+// PointsRule is how many points a result gives.
+type PointsRule struct {
+	Win, Draw, Loss int
+}
+
+// ThreePoints is the usual rule, and the default of a League.
+var ThreePoints = PointsRule{Win: 3, Draw: 1}
+
+// League is a set of matches between teams looked up by name through a
+// flyweight team factory, so each team exists once. Teams are members by id
+// and name rather than by pointer: a bounded factory may evict a team and
+// load it again as a new instance, and the league keeps using the first one.
+// It is safe for concurrent use.
+type League struct {
+	Points PointsRule
+
+	teams   *flyweight.TeamFactory
+	mu      sync.RWMutex
+	byID    map[uint64]*flyweight.Team
+	matches []flyweight.Match
+}
+
+// NewLeague returns a league whose teams come from factory. A nil factory
+// creates a team, with a new id, the first time a name is looked up.
+func NewLeague(factory *flyweight.TeamFactory) *League {
+	if factory == nil {
+		var lastID atomic.Uint64
+		factory = flyweight.NewTeamFactoryWith(func(name string) (*flyweight.Team, error) {
+			if name == "" {
+				return nil, fmt.Errorf("empty team name: %w", flyweight.ErrUnknownKey)
+			}
+			return &flyweight.Team{ID: lastID.Add(1), Name: name}, nil
+		}, flyweight.CacheOptions[string, *flyweight.Team]{})
+	}
+	return &League{Points: ThreePoints, teams: factory, byID: make(map[uint64]*flyweight.Team)}
+}
+
+// Team looks up a team by name and makes it part of the league.
+func (l *League) Team(name string) (*flyweight.Team, error) {
+	t, err := l.teams.Team(name)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.conflict(t); err != nil {
+		return nil, err
+	}
+	return l.join(t), nil
+}
+
+// conflict reports a team whose id is taken by another team of the league.
+func (l *League) conflict(t *flyweight.Team) error {
+	if other, ok := l.byID[t.ID]; ok && other.Name != t.Name {
+		return sameID(other, t)
+	}
+	return nil
+}
+
+func sameID(a, b *flyweight.Team) error {
+	return fmt.Errorf("teams %q and %q have the same id %d", a.Name, b.Name, a.ID)
+}
+
+// join makes t part of the league and returns the instance the league uses
+// for it. l.mu must be held for writing and conflict must have passed.
+func (l *League) join(t *flyweight.Team) *flyweight.Team {
+	if other, ok := l.byID[t.ID]; ok {
+		return other
+	}
+	l.byID[t.ID] = t
+	return t
+}
+
+// Add records a match between two teams of the league.
+func (l *League) Add(m flyweight.Match) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range []uint64{m.LocalID, m.VisitorID} {
+		if _, ok := l.byID[id]; !ok {
+			return fmt.Errorf("match on %s: team %d is not in the league", m.Date.Format("2006-01-02"), id)
+		}
+	}
+	if m.LocalID == m.VisitorID {
+		return errors.New("a team can't play itself")
+	}
+	l.matches = append(l.matches, m)
+	return nil
+}
+
+// Matches returns the matches sorted by date.
+func (l *League) Matches() []flyweight.Match {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.sorted()
+}
+
+func (l *League) sorted() []flyweight.Match {
+	ms := slices.Clone(l.matches)
+	slices.SortStableFunc(ms, func(a, b flyweight.Match) int { return a.Date.Compare(b.Date) })
+	return ms
+}
+
+// Result is the outcome of a match for one team.
+type Result byte
+
+const (
+	Win  Result = 'W'
+	Draw Result = 'D'
+	Loss Result = 'L'
+)
+
+// side is a match seen from one team.
+type side struct {
+	goalsFor, goalsAgainst int
+	shots                  int
+	opponent               uint64
+}
+
+func (s side) result() Result {
+	switch {
+	case s.goalsFor > s.goalsAgainst:
+		return Win
+	case s.goalsFor < s.goalsAgainst:
+		return Loss
+	}
+	return Draw
+}
+
+// sides returns the match from the point of view of the local team and of
+// the visitor.
+func sides(m flyweight.Match) (local, visitor side) {
+	local = side{int(m.LocalScore), int(m.VisitorScore), int(m.LocalShoots), m.VisitorID}
+	visitor = side{int(m.VisitorScore), int(m.LocalScore), int(m.VisitorShoots), m.LocalID}
+	return local, visitor
+}
+
+// Row is a line of the standings table.
+type Row struct {
+	Position     int
+	Team         *flyweight.Team
+	Played       int
+	Won          int
+	Drawn        int
+	Lost         int
+	GoalsFor     int
+	GoalsAgainst int
+	Points       int
+	Shots        int
+	// Form holds the results of the last five matches, oldest first.
+	Form string
+}
+
+func (r Row) GoalDifference() int {
+	return r.GoalsFor - r.GoalsAgainst
+}
+
+// Conversion is the share of shots that were goals, 0 without shots.
+func (r Row) Conversion() float64 {
+	if r.Shots == 0 {
+		return 0
+	}
+	return float64(r.GoalsFor) / float64(r.Shots)
+}
+
+const formLength = 5
+
+// Standings returns the table, ordered by points, goal difference, goals
+// scored and name. Every team of the league has a row, even without
+// matches.
+func (l *League) Standings() []Row {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	rows := make(map[uint64]*Row, len(l.byID))
+	for id, t := range l.byID {
+		rows[id] = &Row{Team: t}
+	}
+	for _, m := range l.sorted() {
+		local, visitor := sides(m)
+		rows[m.LocalID].add(local, l.Points)
+		rows[m.VisitorID].add(visitor, l.Points)
+	}
+	table := make([]Row, 0, len(rows))
+	for _, r := range rows {
+		if len(r.Form) > formLength {
+			r.Form = r.Form[len(r.Form)-formLength:]
+		}
+		table = append(table, *r)
+	}
+	slices.SortFunc(table, func(a, b Row) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.GoalDifference(), a.GoalDifference()),
+			cmp.Compare(b.GoalsFor, a.GoalsFor),
+			cmp.Compare(a.Team.Name, b.Team.Name),
+		)
+	})
+	for i := range table {
+		table[i].Position = i + 1
+	}
+	return table
+}
+
+func (r *Row) add(s side, rule PointsRule) {
+	r.Played++
+	r.GoalsFor += s.goalsFor
+	r.GoalsAgainst += s.goalsAgainst
+	r.Shots += s.shots
+	switch res := s.result(); res {
+	case Win:
+		r.Won++
+		r.Points += rule.Win
+	case Draw:
+		r.Drawn++
+		r.Points += rule.Draw
+	case Loss:
+		r.Lost++
+		r.Points += rule.Loss
+	}
+	r.Form += string(s.result())
+}
+
+// HeadToHead is the record of the matches between two teams, from the
+// point of view of the first one.
+type HeadToHead struct {
+	Team, Opponent *flyweight.Team
+	Played         int
+	Won            int
+	Drawn          int
+	Lost           int
+	GoalsFor       int
+	GoalsAgainst   int
+}
+
+// HeadToHead returns the record of team against opponent.
+func (l *League) HeadToHead(team, opponent string) (HeadToHead, error) {
+	t, o, err := l.pair(team, opponent)
+	if err != nil {
+		return HeadToHead{}, err
+	}
+	h := HeadToHead{Team: t, Opponent: o}
+	for _, s := range l.sidesOf(t.ID) {
+		if s.opponent != o.ID {
+			continue
+		}
+		h.Played++
+		h.GoalsFor += s.goalsFor
+		h.GoalsAgainst += s.goalsAgainst
+		switch s.result() {
+		case Win:
+			h.Won++
+		case Draw:
+			h.Drawn++
+		case Loss:
+			h.Lost++
+		}
+	}
+	return h, nil
+}
+
+// Streaks are runs of consecutive results of a team, by date.
+type Streaks struct {
+	// Current is the result of the last match, repeated Length times.
+	Current         Result
+	Length          int
+	LongestWin      int
+	LongestUnbeaten int
+	LongestLoss     int
+	LongestWinless  int
+}
+
+// Streaks returns the streaks of a team.
+func (l *League) Streaks(team string) (Streaks, error) {
+	t, err := l.member(team)
+	if err != nil {
+		return Streaks{}, err
+	}
+	var st Streaks
+	var win, unbeaten, loss, winless int
+	for _, s := range l.sidesOf(t.ID) {
+		res := s.result()
+		if res == st.Current {
+			st.Length++
+		} else {
+			st.Current, st.Length = res, 1
+		}
+		win = countIf(res == Win, win)
+		unbeaten = countIf(res != Loss, unbeaten)
+		loss = countIf(res == Loss, loss)
+		winless = countIf(res != Win, winless)
+		st.LongestWin = max(st.LongestWin, win)
+		st.LongestUnbeaten = max(st.LongestUnbeaten, unbeaten)
+		st.LongestLoss = max(st.LongestLoss, loss)
+		st.LongestWinless = max(st.LongestWinless, winless)
+	}
+	return st, nil
+}
+
+func countIf(cond bool, n int) int {
+	if cond {
+		return n + 1
+	}
+	return 0
+}
+
+// sidesOf returns the matches of a team by date, from its point of view.
+func (l *League) sidesOf(id uint64) []side {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var out []side
+	for _, m := range l.sorted() {
+		local, visitor := sides(m)
+		switch id {
+		case m.LocalID:
+			out = append(out, local)
+		case m.VisitorID:
+			out = append(out, visitor)
+		}
+	}
+	return out
+}
+
+// member looks up a team that is already part of the league.
+func (l *League) member(name string) (*flyweight.Team, error) {
+	t, err := l.teams.Team(name)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	member, ok := l.byID[t.ID]
+	if !ok || member.Name != t.Name {
+		return nil, fmt.Errorf("team %q is not in the league", name)
+	}
+	return member, nil
+}
+
+func (l *League) pair(a, b string) (*flyweight.Team, *flyweight.Team, error) {
+	ta, err := l.member(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	tb, err := l.member(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ta, tb, nil
+}
@@ -0,0 +1,203 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/patterns/structural/flyweight"
+)
+
+const season = `date,local,visitor,local_score,visitor_score,local_shots,visitor_shots
+2026-08-15,Lions,Bears,1,2,9,4
+2026-08-01,Lions,Tigers,2,1,10,5
+2026-08-01,Bears,Wolves,0,0,4,6
+2026-08-08,Tigers,Bears,1,1,8,8
+2026-08-08,Wolves,Lions,0,3,3,12
+2026-08-15,Tigers,Wolves,4,0,15,2
+`
+
+func newSeason(t *testing.T) *League {
+	t.Helper()
+	l := NewLeague(nil)
+	if err := l.ImportCSV(strings.NewReader(season)); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestStandings(t *testing.T) {
+	rows := newSeason(t).Standings()
+	want := []struct {
+		name                  string
+		w, d, lost, gf, ga, p int
+		shots                 int
+		form                  string
+	}{
+		{"Lions", 2, 0, 1, 6, 3, 6, 31, "WWL"},
+		{"Bears", 1, 2, 0, 3, 2, 5, 16, "DDW"},
+		{"Tigers", 1, 1, 1, 6, 3, 4, 28, "LDW"},
+		{"Wolves", 0, 1, 2, 0, 7, 1, 11, "DLL"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("%d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Position != i+1 || r.Team.Name != w.name || r.Played != 3 || r.Won != w.w || r.Drawn != w.d || r.Lost != w.lost ||
+			r.GoalsFor != w.gf || r.GoalsAgainst != w.ga || r.Points != w.p || r.Shots != w.shots || r.Form != w.form {
+			t.Errorf("row %d = %+v, want %+v", i+1, r, w)
+		}
+	}
+	if gd := rows[3].GoalDifference(); gd != -7 {
+		t.Errorf("Wolves goal difference = %d", gd)
+	}
+	if c := rows[1].Conversion(); c != 3.0/16 {
+		t.Errorf("Bears conversion = %v", c)
+	}
+}
+
+func TestTeamsAreFlyweights(t *testing.T) {
+	l := newSeason(t)
+	lions, _ := l.Team("Lions")
+	if l.Standings()[0].Team != lions {
+		t.Error("standings hold a different Lions instance")
+	}
+
+	// a league over the teams of the default factory
+	fixed := NewLeague(flyweight.NewTeamFactory())
+	a, _ := fixed.Team(flyweight.TEAM_A)
+	b, _ := fixed.Team(flyweight.TEAM_B)
+	if err := fixed.Add(flyweight.Match{LocalID: a.ID, VisitorID: b.ID, LocalScore: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fixed.Team("C"); !errors.Is(err, flyweight.ErrUnknownKey) {
+		t.Errorf("Team(C) = %v", err)
+	}
+	if err := fixed.Add(flyweight.Match{LocalID: a.ID, VisitorID: 99}); err == nil {
+		t.Error("match against a team outside the league added")
+	}
+	if got := fixed.Standings()[0]; got.Team != a || got.Points != 3 {
+		t.Errorf("leader = %+v", got)
+	}
+}
+
+func TestBoundedFactory(t *testing.T) {
+	// the factory keeps a single team, so looking up B evicts A
+	l := NewLeague(flyweight.NewBoundedTeamFactory(flyweight.CacheOptions[string, *flyweight.Team]{MaxEntries: 1}))
+	a, _ := l.Team(flyweight.TEAM_A)
+	b, _ := l.Team(flyweight.TEAM_B)
+	if err := l.Add(flyweight.Match{LocalID: a.ID, VisitorID: b.ID, LocalScore: 1, LocalShoots: 1}); err != nil {
+		t.Fatal(err)
+	}
+	again, err := l.Team(flyweight.TEAM_A)
+	if err != nil || again != a {
+		t.Errorf("Team(A) after eviction = %p, %v; want the league instance %p", again, err, a)
+	}
+	h, err := l.HeadToHead(flyweight.TEAM_B, flyweight.TEAM_A)
+	if err != nil || h.Opponent != a || h.Lost != 1 {
+		t.Errorf("head to head after eviction = %+v, %v", h, err)
+	}
+	if _, err := l.Streaks(flyweight.TEAM_A); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHeadToHead(t *testing.T) {
+	l := newSeason(t)
+	lions, _ := l.Team("Lions")
+	tigers, _ := l.Team("Tigers")
+	l.Add(flyweight.Match{Date: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), LocalID: tigers.ID, VisitorID: lions.ID, LocalScore: 2, VisitorScore: 2})
+	h, err := l.HeadToHead("Lions", "Tigers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Played != 2 || h.Won != 1 || h.Drawn != 1 || h.GoalsFor != 4 || h.GoalsAgainst != 3 {
+		t.Errorf("head to head = %+v", h)
+	}
+	if _, err := l.HeadToHead("Lions", "Eagles"); err == nil {
+		t.Error("head to head against an unknown team")
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	l := newSeason(t)
+	for team, want := range map[string]Streaks{
+		"Lions":  {Current: Loss, Length: 1, LongestWin: 2, LongestUnbeaten: 2, LongestLoss: 1, LongestWinless: 1},
+		"Wolves": {Current: Loss, Length: 2, LongestUnbeaten: 1, LongestLoss: 2, LongestWinless: 3},
+		"Bears":  {Current: Win, Length: 1, LongestWin: 1, LongestUnbeaten: 3, LongestWinless: 2},
+	} {
+		got, err := l.Streaks(team)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s streaks = %+v, want %+v", team, got, want)
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	header := "date,local,visitor,local_score,visitor_score,local_shots,visitor_shots\n"
+	for _, tc := range []struct{ doc, want string }{
+		{"day,home\n", "header must be"},
+		{header + "2026-13-01,A,B,1,0,3,1\n", "line 2: date"},
+		{header + "2026-08-01,A,A,1,0,3,1\n", "can't play itself"},
+		{header + "2026-08-01,A,B,1,x,3,1\n", "visitor_score"},
+		{header + "2026-08-01,A,B,300,0,3,1\n", "local_score"},
+		{header + "2026-08-01,A,B,5,0,3,1\n", "more goals than shots"},
+		{header + "2026-08-01,A,B,1\n", "wrong number of fields"},
+		{header + "2026-08-01,,B,1,0,3,1\n", "empty team name"},
+	} {
+		l := NewLeague(nil)
+		err := l.ImportCSV(strings.NewReader(tc.doc))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: error %v doesn't mention %q", tc.doc, err, tc.want)
+		}
+		if len(l.Matches()) != 0 {
+			t.Errorf("%q: matches added", tc.doc)
+		}
+	}
+
+	l := NewLeague(nil)
+	err := l.ImportCSV(strings.NewReader(header + "2026-08-01,A,B,1,0,3,1\n2026-08-08,C,D,x,0,3,1\n"))
+	if err == nil {
+		t.Fatal("invalid file imported")
+	}
+	if rows := l.Standings(); len(rows) != 0 {
+		t.Errorf("a rejected import added teams: %+v", rows)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf, newSeason(t).Standings()); err != nil {
+		t.Fatal(err)
+	}
+	want := `  #    Team  P  W  D  L  GF  GA  GD  Pts  Conv  Form
+  1   Lions  3  2  0  1   6   3  +3    6   19%   WWL
+  2   Bears  3  1  2  0   3   2  +1    5   19%   DDW
+  3  Tigers  3  1  1  1   6   3  +3    4   21%   LDW
+  4  Wolves  3  0  1  2   0   7  -7    1    0%   DLL
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, newSeason(t).Standings()); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0]["team"] != "Lions" || rows[0]["goal_difference"] != 3.0 || rows[3]["form"] != "DLL" {
+		t.Errorf("rows = %v", rows)
+	}
+}